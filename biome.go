@@ -12,70 +12,42 @@ type Biome interface {
 	// Height returns a height value produced for the biome at a specific x and z in the world. Biomes generally use
 	// noise to return a height value.
 	Height(x, z float64) float64
+	// ID returns the Bedrock biome ID of the biome. It is written to the chunks generated, so that clients render the
+	// foliage, water and sky colours of the biome.
+	ID() uint32
 }
 
-type biomeSet struct {
-	Plains    Biome
-	Ocean     Biome
-	Mountains Biome
-}
-
-func newBiomeSet(seed int64) biomeSet {
+// DefaultBiomes returns a BiomeRegistry holding the biomes of package biome. Oceans are placed where the
+// continentalness is low, rivers where the weirdness is close to 0.5 and mountains far inland where the erosion is low.
+// The remaining land biomes are laid out by humidity and temperature. The noise of the biomes is derived from
+// Config.Seed.
+func DefaultBiomes(conf Config) *BiomeRegistry {
+	conf = conf.withDefaults()
+	seed := conf.Seed
 	n := f.Noise(seed, 3, 2, 0.5).Norm()
 
-	d := n.WarpDomain(0.2, 70)
-	return biomeSet{
-		Plains: &biome.Plains{Noise: n.WarpDomain(0.4, 40)},
-		Ocean:  &biome.Ocean{Noise: n},
-		Mountains: &biome.Mountains{Noise: f.Sum(
-			d,
-			f.Noise(seed, 3, 3, 0.6).
-				Norm().
-				MulF(d.Slope(0.003).
-					Mul(10)),
-		)},
-	}
-}
+	w := n.WarpDomain(0.4, 40)
+	land := Interval{0.42, 1}
+
+	r := NewBiomeRegistry()
+	r.Register(&biome.Ocean{Noise: n, SeaLevel: conf.SeaLevel}, ClimateRange{Continentalness: Interval{0, 0.42}})
+	r.Register(&biome.River{Noise: n, SeaLevel: conf.SeaLevel}, ClimateRange{Continentalness: land, Weirdness: Interval{0.49, 0.51}})
+	// The mountains are built from the same noise as n, but with analytic derivatives, so that the slope that their
+	// height depends on is cheap to compute. Only the slope of the height itself, which is needed far less often, is
+	// approximated from the derivatives.
+	d := f.NoiseD(seed, 3, 2, 0.5).Norm().WarpDomain(0.2, 70)
+	peaks := f.NoiseD(seed, 3, 3, 0.6).Norm()
+	r.Register(&biome.Mountains{
+		Noise: f.Sum(d.F(), peaks.F().MulF(d.Slope().Mul(10))),
+		Slope: f.SumD(d, peaks.MulF(d.SlopeD(0.003).Mul(10))).Slope(),
+	}, ClimateRange{Continentalness: Interval{0.5, 1}, Erosion: Interval{0, 0.38}})
+	r.Register(&biome.Swamp{Noise: w, SeaLevel: conf.SeaLevel}, ClimateRange{Continentalness: land, Humidity: Interval{0.65, 1}, Temperature: Interval{0.65, 1}, Erosion: Interval{0.55, 1}})
 
-func (b biomeSet) selectBiome(hum, temp float64) Biome {
-	switch {
-	case hum < 0.25:
-		switch {
-		case temp < 0.7:
-			return b.Ocean
-		case temp < 0.85:
-			// river
-		default:
-			// swamp
-		}
-	case hum < 0.6:
-		switch {
-		case temp < 0.25:
-			// ice plains
-		case temp < 0.75:
-			return b.Plains
-		default:
-			// desert
-		}
-	case hum < 0.8:
-		switch {
-		case temp < 0.25:
-			// taiga
-		case temp < 0.75:
-			// forest
-		default:
-			// birch forest
-		}
-	default:
-		switch {
-		case temp < 0.2:
-			return b.Mountains
-		case temp < 0.4:
-			return b.Mountains // small mountains
-		default:
-			return b.Mountains
-			//return b.Ocean
-		}
-	}
-	return b.Plains
+	r.Register(&biome.IcePlains{Noise: w, SeaLevel: conf.SeaLevel}, ClimateRange{Continentalness: land, Humidity: Interval{0, 0.5}, Temperature: Interval{0, 0.35}})
+	r.Register(&biome.Taiga{Noise: w, SeaLevel: conf.SeaLevel}, ClimateRange{Continentalness: land, Humidity: Interval{0.5, 1}, Temperature: Interval{0, 0.35}})
+	r.Register(&biome.Plains{Noise: w, SeaLevel: conf.SeaLevel}, ClimateRange{Continentalness: land, Humidity: Interval{0, 0.4}, Temperature: Interval{0.35, 0.65}})
+	r.Register(&biome.Forest{Noise: w, SeaLevel: conf.SeaLevel}, ClimateRange{Continentalness: land, Humidity: Interval{0.4, 0.6}, Temperature: Interval{0.35, 0.65}})
+	r.Register(&biome.BirchForest{Noise: w, SeaLevel: conf.SeaLevel}, ClimateRange{Continentalness: land, Humidity: Interval{0.6, 1}, Temperature: Interval{0.35, 0.65}})
+	r.Register(&biome.Desert{Noise: n.WarpDomain(0.3, 60)}, ClimateRange{Continentalness: land, Humidity: Interval{0, 0.5}, Temperature: Interval{0.65, 1}})
+	return r
 }
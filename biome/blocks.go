@@ -1,13 +1,33 @@
 package biome
 
 import (
+	"fmt"
 	"github.com/df-mc/dragonfly/server/block"
 	"github.com/df-mc/dragonfly/server/world"
+	"github.com/df-mc/dragonfly/server/world/chunk"
 )
 
 var (
-	grass = world.BlockRuntimeID(block.Grass{})
-	dirt  = world.BlockRuntimeID(block.Dirt{})
-	stone = world.BlockRuntimeID(block.Stone{})
-	sand  = world.BlockRuntimeID(block.Sand{})
+	air       = world.BlockRuntimeID(block.Air{})
+	grass     = world.BlockRuntimeID(block.Grass{})
+	dirt      = world.BlockRuntimeID(block.Dirt{})
+	stone     = world.BlockRuntimeID(block.Stone{})
+	sand      = world.BlockRuntimeID(block.Sand{})
+	gravel    = world.BlockRuntimeID(block.Gravel{})
+	clay      = world.BlockRuntimeID(block.Clay{})
+	podzol    = world.BlockRuntimeID(block.Podzol{})
+	sandstone = world.BlockRuntimeID(block.Sandstone{Type: block.NormalSandstone()})
+	ice       = stateRuntimeID("minecraft:ice", map[string]interface{}{})
+	snowLayer = stateRuntimeID("minecraft:snow_layer", map[string]interface{}{"height": int32(0), "covered_bit": false})
+	cactus    = stateRuntimeID("minecraft:cactus", map[string]interface{}{"age": int32(0)})
 )
+
+// stateRuntimeID returns the runtime ID of a block state by its name and properties. It is used for blocks that are
+// not (yet) implemented by Dragonfly. stateRuntimeID panics if the block state does not exist.
+func stateRuntimeID(name string, properties map[string]interface{}) uint32 {
+	rid, ok := chunk.StateToRuntimeID(name, properties)
+	if !ok {
+		panic(fmt.Sprintf("cannot find block state %v %v", name, properties))
+	}
+	return rid
+}
@@ -0,0 +1,43 @@
+package biome
+
+import (
+	"math/rand"
+)
+
+// Decorator places a feature, such as a tree or a flower, on top of the terrain after it has been generated.
+// Features may extend beyond the chunk that they are rooted in. A feature is therefore placed again for every chunk
+// that it could reach, and only the blocks within that chunk are actually placed. To make sure a feature is the same in
+// every chunk, a Decorator must only use the random source passed to Decorate and must not depend on blocks outside
+// the column that the feature is rooted in.
+type Decorator interface {
+	// Decorate places the feature rooted on top of the surface block at x, y, z, which are absolute coordinates in the
+	// world. Blocks are placed using the Placer passed. The rand.Rand passed is seeded using the position of the
+	// feature.
+	Decorate(p Placer, x, y, z int, r *rand.Rand)
+}
+
+// Placer places the blocks of features in the chunk being decorated. All coordinates are absolute coordinates in the
+// world.
+type Placer interface {
+	// SetBlock sets the block at x, y, z to the runtime ID passed. Positions outside the chunk being decorated are
+	// ignored.
+	SetBlock(x, y, z int, rid uint32)
+	// Block returns the runtime ID of the block at x, y, z. If the position is outside the chunk being decorated,
+	// false is returned, except for the surface block of the column that the feature is rooted in, which is always
+	// available.
+	Block(x, y, z int) (uint32, bool)
+}
+
+// placeInAir places a block at x, y, z using the Placer passed if the block currently there is air.
+func placeInAir(p Placer, x, y, z int, rid uint32) {
+	if b, ok := p.Block(x, y, z); ok && b == air {
+		p.SetBlock(x, y, z, rid)
+	}
+}
+
+// Decoration is a Decorator along with the average amount of features it places per chunk. At most one feature is
+// placed per column, so the Count of all Decorations of a biome must not add up to more than 256.
+type Decoration struct {
+	Decorator Decorator
+	Count     float64
+}
@@ -0,0 +1,40 @@
+package biome
+
+import (
+	vanilla "github.com/df-mc/dragonfly/server/world/biome"
+	"github.com/df-mc/dragonfly/server/world/chunk"
+	"github.com/df-mc/gen/f"
+)
+
+type Desert struct {
+	Noise f.F
+}
+
+func (d *Desert) CoverGround(x, z uint8, absX, absZ int32, height int, c *chunk.Chunk) {
+	// A layer of 3-5 blocks of sand covers 4 blocks of sandstone.
+	depth := 3 + int(d.Noise(float64(absX)*4, float64(absZ)*4)*3)
+	minY := c.Range().Min()
+	for y := height; y > height-depth-4 && y > minY; y-- {
+		b := sand
+		if y <= height-depth {
+			b = sandstone
+		}
+		c.SetBlock(x, int16(y), z, 0, b)
+	}
+}
+
+func (d *Desert) Height(x, z float64) float64 {
+	return d.Noise(x, z)*0.08 + 0.1
+}
+
+func (*Desert) ID() uint32 {
+	return uint32(vanilla.Desert{}.EncodeBiome())
+}
+
+func (*Desert) Decorations() []Decoration {
+	return desertDecorations
+}
+
+var desertDecorations = []Decoration{
+	{Decorator: Cactus{MaxHeight: 3}, Count: 1.5},
+}
@@ -0,0 +1,74 @@
+package biome
+
+import (
+	"github.com/df-mc/dragonfly/server/block"
+	vanilla "github.com/df-mc/dragonfly/server/world/biome"
+	"github.com/df-mc/dragonfly/server/world/chunk"
+	"github.com/df-mc/gen/f"
+)
+
+type Forest struct {
+	Noise    f.F
+	SeaLevel int
+}
+
+func (fo *Forest) CoverGround(x, z uint8, absX, absZ int32, height int, c *chunk.Chunk) {
+	if height <= fo.SeaLevel+1 {
+		coverSeabed(x, z, absX, absZ, height, fo.SeaLevel, fo.Noise, c)
+		return
+	}
+	coverSoil(x, z, height, grass, c)
+}
+
+func (fo *Forest) Height(x, z float64) float64 {
+	return fo.Noise(x, z)*0.16 + 0.08
+}
+
+func (*Forest) ID() uint32 {
+	return uint32(vanilla.Forest{}.EncodeBiome())
+}
+
+func (*Forest) Decorations() []Decoration {
+	return forestDecorations
+}
+
+var forestDecorations = []Decoration{
+	{Decorator: Tree{Wood: block.OakWood(), MinHeight: 4, MaxHeight: 6}, Count: 8},
+	{Decorator: Tree{Wood: block.BirchWood(), MinHeight: 5, MaxHeight: 7}, Count: 2},
+	{Decorator: Flowers{Types: []block.FlowerType{block.Dandelion(), block.Poppy(), block.LilyOfTheValley()}}, Count: 2},
+	{Decorator: TallGrass{}, Count: 12},
+}
+
+type BirchForest struct {
+	Noise    f.F
+	SeaLevel int
+}
+
+func (b *BirchForest) CoverGround(x, z uint8, absX, absZ int32, height int, c *chunk.Chunk) {
+	if height <= b.SeaLevel+1 {
+		coverSeabed(x, z, absX, absZ, height, b.SeaLevel, b.Noise, c)
+		return
+	}
+	coverSoil(x, z, height, grass, c)
+}
+
+func (b *BirchForest) Height(x, z float64) float64 {
+	// Squaring the noise flattens the lower half of its range, so that birch forests are mostly flat with a few
+	// gentle, rounded hills, unlike the evenly hilly terrain of a Forest.
+	v := b.Noise(x, z)
+	return v*v*0.14 + 0.08
+}
+
+func (*BirchForest) ID() uint32 {
+	return uint32(vanilla.BirchForest{}.EncodeBiome())
+}
+
+func (*BirchForest) Decorations() []Decoration {
+	return birchForestDecorations
+}
+
+var birchForestDecorations = []Decoration{
+	{Decorator: Tree{Wood: block.BirchWood(), MinHeight: 5, MaxHeight: 7}, Count: 9},
+	{Decorator: Flowers{Types: []block.FlowerType{block.Dandelion(), block.Poppy(), block.Allium()}}, Count: 2},
+	{Decorator: TallGrass{}, Count: 12},
+}
@@ -0,0 +1,56 @@
+package biome
+
+import (
+	"github.com/df-mc/dragonfly/server/world/chunk"
+	"github.com/df-mc/gen/f"
+)
+
+// Generic is a biome of which the height, ground cover and decorations are fully described by its fields, so that it
+// may be created from data, such as a biome definition in a TOML file.
+type Generic struct {
+	// BiomeID is the Bedrock biome ID of the biome.
+	BiomeID uint32
+	// Elevation returns the height of the biome at a specific x and z in the world.
+	Elevation f.F
+	// Noise is a function returning values in the range [0-1) used to create patches of clay on the seabed.
+	Noise f.F
+	// SeaLevel is the Y level of the sea level. Columns close to or below the sea level are covered with a seabed
+	// instead of the Top and Filler blocks.
+	SeaLevel int
+	// Top is the runtime ID of the block placed at the surface of every column. Filler is the runtime ID of the block
+	// placed in the FillerDepth blocks below the surface.
+	Top, Filler uint32
+	FillerDepth int
+	// CaveSize is the size of the caves carved below the biome. A CaveSize of 0 disables caves.
+	CaveSize float64
+	// Features holds the Decorations placed on the surface of the biome.
+	Features []Decoration
+}
+
+func (g *Generic) CoverGround(x, z uint8, absX, absZ int32, height int, c *chunk.Chunk) {
+	if height <= g.SeaLevel+1 {
+		coverSeabed(x, z, absX, absZ, height, g.SeaLevel, g.Noise, c)
+		return
+	}
+	c.SetBlock(x, int16(height), z, 0, g.Top)
+	minY := c.Range().Min()
+	for y := height - 1; y >= height-g.FillerDepth && y > minY; y-- {
+		c.SetBlock(x, int16(y), z, 0, g.Filler)
+	}
+}
+
+func (g *Generic) Height(x, z float64) float64 {
+	return g.Elevation(x, z)
+}
+
+func (g *Generic) ID() uint32 {
+	return g.BiomeID
+}
+
+func (g *Generic) Caves() float64 {
+	return g.CaveSize
+}
+
+func (g *Generic) Decorations() []Decoration {
+	return g.Features
+}
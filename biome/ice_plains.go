@@ -0,0 +1,39 @@
+package biome
+
+import (
+	vanilla "github.com/df-mc/dragonfly/server/world/biome"
+	"github.com/df-mc/dragonfly/server/world/chunk"
+	"github.com/df-mc/gen/f"
+)
+
+type IcePlains struct {
+	Noise    f.F
+	SeaLevel int
+}
+
+func (i *IcePlains) CoverGround(x, z uint8, absX, absZ int32, height int, c *chunk.Chunk) {
+	switch {
+	case height < i.SeaLevel:
+		// The water above the ground is frozen at the surface. The rest of the water is filled in below the ice.
+		coverSeabed(x, z, absX, absZ, height, i.SeaLevel, i.Noise, c)
+		if i.SeaLevel <= c.Range().Max() {
+			c.SetBlock(x, int16(i.SeaLevel), z, 0, ice)
+		}
+		return
+	case height <= i.SeaLevel+1:
+		coverSeabed(x, z, absX, absZ, height, i.SeaLevel, i.Noise, c)
+	default:
+		coverSoil(x, z, height, grass, c)
+	}
+	if height < c.Range().Max() {
+		c.SetBlock(x, int16(height+1), z, 0, snowLayer)
+	}
+}
+
+func (i *IcePlains) Height(x, z float64) float64 {
+	return i.Noise(x, z)*0.12 + 0.07
+}
+
+func (*IcePlains) ID() uint32 {
+	return uint32(vanilla.SnowyPlains{}.EncodeBiome())
+}
@@ -1,18 +1,24 @@
 package biome
 
 import (
+	vanilla "github.com/df-mc/dragonfly/server/world/biome"
 	"github.com/df-mc/dragonfly/server/world/chunk"
 	"github.com/df-mc/gen/f"
-	"math"
 )
 
 type Mountains struct {
 	Noise f.F
+	// Slope returns the slope of Noise, which is used to cover flat areas with grass. It is separate from Noise, so
+	// that Height does not pay for computing it. If nil, the slope is estimated from Noise using f.F.Slope.
+	Slope f.F
 }
 
 func (m *Mountains) CoverGround(x, z uint8, absX, absZ int32, height int, c *chunk.Chunk) {
-	s := slope(float64(absX), float64(absZ), m.Height)
-	if s < 0.01 {
+	slope := m.Slope
+	if slope == nil {
+		slope = m.Noise.Slope(0.003)
+	}
+	if slope(float64(absX), float64(absZ))*0.6 < 0.01 {
 		c.SetBlock(x, int16(height), z, 0, grass)
 	}
 }
@@ -21,17 +27,10 @@ func (m *Mountains) Height(x, z float64) float64 {
 	return m.Noise(x, z) * 0.6
 }
 
-// slope calculates roughly the slope at a specific x and z value in the noise function passed.
-func slope(x, y float64, noise func(x, z float64) float64) float64 {
-	dx, dy := 0.003, 0.003
-
-	x1 := noise(x-dx/2, y)
-	x2 := noise(x+dx/2, y)
-
-	y1 := noise(x, y-dy/2)
-	y2 := noise(x, y+dy/2)
-
-	dX, dY := (x2-x1)/dx, (y2-y1)/dy
+func (*Mountains) ID() uint32 {
+	return uint32(vanilla.StonyPeaks{}.EncodeBiome())
+}
 
-	return math.Sqrt(dX*dX + dY*dY)
+func (*Mountains) Caves() float64 {
+	return 1.3
 }
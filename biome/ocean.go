@@ -1,20 +1,69 @@
 package biome
 
 import (
+	vanilla "github.com/df-mc/dragonfly/server/world/biome"
 	"github.com/df-mc/dragonfly/server/world/chunk"
 	"github.com/df-mc/gen/f"
 )
 
 type Ocean struct {
-	Noise f.F
+	Noise    f.F
+	SeaLevel int
 }
 
-func (o *Ocean) CoverGround(x, z uint8, _, _ int32, height int, c *chunk.Chunk) {
-	c.SetBlock(x, int16(height), z, 0, sand)
-	c.SetBlock(x, int16(height-1), z, 0, sand)
-	c.SetBlock(x, int16(height-2), z, 0, sand)
+func (o *Ocean) CoverGround(x, z uint8, absX, absZ int32, height int, c *chunk.Chunk) {
+	coverSeabed(x, z, absX, absZ, height, o.SeaLevel, o.Noise, c)
 }
 
 func (o *Ocean) Height(x, z float64) float64 {
 	return o.Noise(x, z)*0.05 + 0.025
 }
+
+func (*Ocean) ID() uint32 {
+	return uint32(vanilla.Ocean{}.EncodeBiome())
+}
+
+func (*Ocean) Caves() float64 {
+	return 0.6
+}
+
+// coverSeabed covers the ground of a column with the seabed found at its depth below the sea level passed. The noise
+// passed is used to create patches of clay.
+func coverSeabed(x, z uint8, absX, absZ int32, height, seaLevel int, noise f.F, c *chunk.Chunk) {
+	b := seabed(seaLevel-height, noise(float64(absX)*4, float64(absZ)*4))
+	coverColumn(x, z, height, c, b, b, sand)
+}
+
+// coverSoil covers the ground of a column with the top block passed, with two blocks of dirt below it.
+func coverSoil(x, z uint8, height int, top uint32, c *chunk.Chunk) {
+	coverColumn(x, z, height, c, top, dirt, dirt)
+}
+
+// coverColumn places the blocks passed in a column from the height passed downwards. Blocks that would be placed at
+// or below the bottom of the chunk are left out.
+func coverColumn(x, z uint8, height int, c *chunk.Chunk, blocks ...uint32) {
+	c.SetBlock(x, int16(height), z, 0, blocks[0])
+	minY := c.Range().Min()
+	for i, b := range blocks[1:] {
+		if y := height - 1 - i; y > minY {
+			c.SetBlock(x, int16(y), z, 0, b)
+		}
+	}
+}
+
+// seabed returns the block that covers the ground at a specific depth below sea level. Shallow water has a sandy
+// floor, deep water a gravel floor and the depths in between are covered with sand with patches of clay, depending on
+// the noise value n passed, which should be in the range [0-1).
+func seabed(depth int, n float64) uint32 {
+	switch {
+	case depth < 4:
+		return sand
+	case depth < 10:
+		if n > 0.6 {
+			return clay
+		}
+		return sand
+	default:
+		return gravel
+	}
+}
@@ -1,20 +1,40 @@
 package biome
 
 import (
+	"github.com/df-mc/dragonfly/server/block"
+	vanilla "github.com/df-mc/dragonfly/server/world/biome"
 	"github.com/df-mc/dragonfly/server/world/chunk"
 	"github.com/df-mc/gen/f"
 )
 
 type Plains struct {
-	Noise f.F
+	Noise    f.F
+	SeaLevel int
 }
 
-func (p *Plains) CoverGround(x, z uint8, _, _ int32, height int, c *chunk.Chunk) {
-	c.SetBlock(x, int16(height), z, 0, grass)
-	c.SetBlock(x, int16(height-1), z, 0, dirt)
-	c.SetBlock(x, int16(height-2), z, 0, dirt)
+func (p *Plains) CoverGround(x, z uint8, absX, absZ int32, height int, c *chunk.Chunk) {
+	if height <= p.SeaLevel+1 {
+		// The column is close to or below the sea level, so we create a beach here.
+		coverSeabed(x, z, absX, absZ, height, p.SeaLevel, p.Noise, c)
+		return
+	}
+	coverSoil(x, z, height, grass, c)
 }
 
 func (p *Plains) Height(x, z float64) float64 {
 	return p.Noise(x, z)*0.15 + 0.07
 }
+
+func (*Plains) ID() uint32 {
+	return uint32(vanilla.Plains{}.EncodeBiome())
+}
+
+func (*Plains) Decorations() []Decoration {
+	return plainsDecorations
+}
+
+var plainsDecorations = []Decoration{
+	{Decorator: Tree{Wood: block.OakWood(), MinHeight: 4, MaxHeight: 6}, Count: 0.3},
+	{Decorator: Flowers{Types: []block.FlowerType{block.Dandelion(), block.Poppy(), block.AzureBluet(), block.OxeyeDaisy(), block.Cornflower()}}, Count: 3},
+	{Decorator: TallGrass{}, Count: 40},
+}
@@ -0,0 +1,61 @@
+package biome
+
+import (
+	"github.com/df-mc/dragonfly/server/block"
+	"github.com/df-mc/dragonfly/server/world"
+	"math/rand"
+)
+
+// TallGrass is a Decorator that places a single block of tall grass or fern on grass.
+type TallGrass struct {
+	// Fern specifies if ferns should be placed instead of normal tall grass.
+	Fern bool
+}
+
+func (t TallGrass) Decorate(p Placer, x, y, z int, _ *rand.Rand) {
+	typ := block.NormalGrass()
+	if t.Fern {
+		typ = block.Fern()
+	}
+	placeOn(p, x, y, z, grass, world.BlockRuntimeID(block.TallGrass{Type: typ}))
+}
+
+// Flowers is a Decorator that places a single flower on grass. The type of the flower is selected randomly from
+// Types.
+type Flowers struct {
+	Types []block.FlowerType
+}
+
+func (f Flowers) Decorate(p Placer, x, y, z int, r *rand.Rand) {
+	if len(f.Types) == 0 {
+		return
+	}
+	placeOn(p, x, y, z, grass, world.BlockRuntimeID(block.Flower{Type: f.Types[r.Intn(len(f.Types))]}))
+}
+
+// Cactus is a Decorator that places a cactus on sand.
+type Cactus struct {
+	// MaxHeight is the maximum height of the cactus. The height of every cactus is selected randomly between 1 and
+	// MaxHeight.
+	MaxHeight int
+}
+
+func (c Cactus) Decorate(p Placer, x, y, z int, r *rand.Rand) {
+	if b, ok := p.Block(x, y, z); !ok || b != sand {
+		return
+	}
+	height := 1
+	if c.MaxHeight > 1 {
+		height += r.Intn(c.MaxHeight)
+	}
+	for i := 1; i <= height; i++ {
+		placeInAir(p, x, y+i, z, cactus)
+	}
+}
+
+// placeOn places a block on top of the surface block at x, y, z if the surface block is the ground block passed.
+func placeOn(p Placer, x, y, z int, ground, rid uint32) {
+	if b, ok := p.Block(x, y, z); ok && b == ground {
+		placeInAir(p, x, y+1, z, rid)
+	}
+}
@@ -0,0 +1,28 @@
+package biome
+
+import (
+	vanilla "github.com/df-mc/dragonfly/server/world/biome"
+	"github.com/df-mc/dragonfly/server/world/chunk"
+	"github.com/df-mc/gen/f"
+)
+
+type River struct {
+	Noise    f.F
+	SeaLevel int
+}
+
+func (r *River) CoverGround(x, z uint8, absX, absZ int32, height int, c *chunk.Chunk) {
+	coverSeabed(x, z, absX, absZ, height, r.SeaLevel, r.Noise, c)
+}
+
+func (r *River) Height(x, z float64) float64 {
+	return r.Noise(x, z)*0.02 + 0.05
+}
+
+func (*River) ID() uint32 {
+	return uint32(vanilla.River{}.EncodeBiome())
+}
+
+func (*River) Caves() float64 {
+	return 0.6
+}
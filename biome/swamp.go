@@ -0,0 +1,44 @@
+package biome
+
+import (
+	"github.com/df-mc/dragonfly/server/block"
+	vanilla "github.com/df-mc/dragonfly/server/world/biome"
+	"github.com/df-mc/dragonfly/server/world/chunk"
+	"github.com/df-mc/gen/f"
+)
+
+type Swamp struct {
+	Noise    f.F
+	SeaLevel int
+}
+
+func (s *Swamp) CoverGround(x, z uint8, absX, absZ int32, height int, c *chunk.Chunk) {
+	if height <= s.SeaLevel {
+		// Flooded parts of the swamp have a muddy floor of dirt with patches of clay.
+		top := dirt
+		if s.Noise(float64(absX)*4, float64(absZ)*4) > 0.6 {
+			top = clay
+		}
+		coverSoil(x, z, height, top, c)
+		return
+	}
+	coverSoil(x, z, height, grass, c)
+}
+
+func (s *Swamp) Height(x, z float64) float64 {
+	return s.Noise(x, z)*0.05 + 0.075
+}
+
+func (*Swamp) ID() uint32 {
+	return uint32(vanilla.Swamp{}.EncodeBiome())
+}
+
+func (*Swamp) Decorations() []Decoration {
+	return swampDecorations
+}
+
+var swampDecorations = []Decoration{
+	{Decorator: Tree{Wood: block.OakWood(), MinHeight: 5, MaxHeight: 7}, Count: 2},
+	{Decorator: Flowers{Types: []block.FlowerType{block.BlueOrchid()}}, Count: 1},
+	{Decorator: TallGrass{}, Count: 20},
+}
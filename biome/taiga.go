@@ -0,0 +1,42 @@
+package biome
+
+import (
+	vanilla "github.com/df-mc/dragonfly/server/world/biome"
+	"github.com/df-mc/dragonfly/server/world/chunk"
+	"github.com/df-mc/gen/f"
+)
+
+type Taiga struct {
+	Noise    f.F
+	SeaLevel int
+}
+
+func (t *Taiga) CoverGround(x, z uint8, absX, absZ int32, height int, c *chunk.Chunk) {
+	if height <= t.SeaLevel+1 {
+		coverSeabed(x, z, absX, absZ, height, t.SeaLevel, t.Noise, c)
+		return
+	}
+	top := grass
+	if t.Noise(float64(absX)*4, float64(absZ)*4) > 0.55 {
+		top = podzol
+	}
+	coverSoil(x, z, height, top, c)
+}
+
+func (t *Taiga) Height(x, z float64) float64 {
+	return t.Noise(x, z)*0.18 + 0.09
+}
+
+func (*Taiga) ID() uint32 {
+	return uint32(vanilla.Taiga{}.EncodeBiome())
+}
+
+func (*Taiga) Decorations() []Decoration {
+	return taigaDecorations
+}
+
+var taigaDecorations = []Decoration{
+	{Decorator: SpruceTree{MinHeight: 6, MaxHeight: 9}, Count: 8},
+	{Decorator: TallGrass{Fern: true}, Count: 8},
+	{Decorator: TallGrass{}, Count: 4},
+}
@@ -0,0 +1,128 @@
+package biome
+
+import (
+	"github.com/df-mc/dragonfly/server/block"
+	"github.com/df-mc/dragonfly/server/block/cube"
+	"github.com/df-mc/dragonfly/server/world"
+	"math/rand"
+)
+
+// Tree is a Decorator that places a tree with a straight trunk and a round canopy of leaves, such as oak and birch
+// trees, on grass, dirt or podzol. The canopy extends up to 2 blocks from the trunk.
+type Tree struct {
+	// Wood is the type of wood of the logs and leaves of the tree.
+	Wood block.WoodType
+	// MinHeight and MaxHeight are the minimum and maximum height of the trunk of the tree. If MaxHeight is lower than
+	// MinHeight, the trunk is always MinHeight blocks tall.
+	MinHeight, MaxHeight int
+}
+
+func (t Tree) Decorate(p Placer, x, y, z int, r *rand.Rand) {
+	if !onSoil(p, x, y, z) {
+		return
+	}
+	log, leaves := woodBlocks(t.Wood)
+	height := randomHeight(r, t.MinHeight, t.MaxHeight)
+	top := y + height
+
+	for ly := top - 3; ly <= top; ly++ {
+		radius := 2
+		if ly >= top-1 {
+			radius = 1
+		}
+		for dx := -radius; dx <= radius; dx++ {
+			for dz := -radius; dz <= radius; dz++ {
+				// The corners of every layer are left out at random, and always in the top layer, to round off the
+				// canopy.
+				corner := abs(dx) == radius && abs(dz) == radius
+				if corner && (ly == top || r.Intn(2) == 0) {
+					continue
+				}
+				placeInAir(p, x+dx, ly, z+dz, leaves)
+			}
+		}
+	}
+	// The trunk is placed after the canopy, so that the leaves it passes through are replaced.
+	for ly := y + 1; ly < top; ly++ {
+		placeLog(p, x, ly, z, log, leaves)
+	}
+}
+
+// SpruceTree is a Decorator that places a tall spruce tree with a cone-shaped canopy on grass, dirt or podzol. The
+// canopy extends up to 3 blocks from the trunk.
+type SpruceTree struct {
+	// MinHeight and MaxHeight are the minimum and maximum height of the trunk of the tree. If MaxHeight is lower than
+	// MinHeight, the trunk is always MinHeight blocks tall.
+	MinHeight, MaxHeight int
+}
+
+func (t SpruceTree) Decorate(p Placer, x, y, z int, r *rand.Rand) {
+	if !onSoil(p, x, y, z) {
+		return
+	}
+	log, leaves := woodBlocks(block.SpruceWood())
+	height := randomHeight(r, t.MinHeight, t.MaxHeight)
+	top := y + height
+	// The canopy starts 1 or 2 blocks above the ground and gets wider towards the bottom, alternating between a wider
+	// and a narrower layer.
+	bottom := y + 1 + r.Intn(2)
+	maxRadius := 2 + r.Intn(2)
+
+	placeInAir(p, x, top+1, z, leaves)
+	for ly := top; ly >= bottom; ly-- {
+		i := top - ly
+		radius := (i + 1) / 2
+		if i%2 == 0 && i > 0 {
+			radius--
+		}
+		if radius > maxRadius {
+			radius = maxRadius
+		}
+		for dx := -radius; dx <= radius; dx++ {
+			for dz := -radius; dz <= radius; dz++ {
+				if radius > 0 && abs(dx) == radius && abs(dz) == radius {
+					continue
+				}
+				placeInAir(p, x+dx, ly, z+dz, leaves)
+			}
+		}
+	}
+	for ly := y + 1; ly <= top; ly++ {
+		placeLog(p, x, ly, z, log, leaves)
+	}
+}
+
+// onSoil checks if the block at x, y, z, which a tree is planted on, is grass, dirt or podzol.
+func onSoil(p Placer, x, y, z int) bool {
+	b, ok := p.Block(x, y, z)
+	return ok && (b == grass || b == dirt || b == podzol)
+}
+
+// randomHeight returns a random height between min and max, both inclusive. If max is lower than min, min is
+// returned.
+func randomHeight(r *rand.Rand, min, max int) int {
+	if max <= min {
+		return min
+	}
+	return min + r.Intn(max-min+1)
+}
+
+// woodBlocks returns the runtime IDs of the log and the leaves of a type of wood.
+func woodBlocks(wood block.WoodType) (log, leaves uint32) {
+	return world.BlockRuntimeID(block.Log{Wood: wood, Axis: cube.Y}), world.BlockRuntimeID(block.Leaves{Wood: wood})
+}
+
+// placeLog places a log at x, y, z if the block there is air or the leaves passed.
+func placeLog(p Placer, x, y, z int, log, leaves uint32) {
+	if b, ok := p.Block(x, y, z); ok && (b == air || b == leaves) {
+		p.SetBlock(x, y, z, log)
+	}
+}
+
+// abs returns the absolute value of v.
+func abs(v int) int {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
@@ -0,0 +1,70 @@
+package gen
+
+import (
+	"container/list"
+	"github.com/df-mc/dragonfly/server/world"
+	"sync"
+)
+
+const (
+	// regionShift is the amount of bits that a chunk coordinate is shifted to obtain the coordinate of its region.
+	regionShift = 5
+	// regionSize is the width in chunks of a region. All chunks in a region share the same voronoi cells, which are
+	// computed once for the whole region and then cached.
+	regionSize = 1 << regionShift
+)
+
+// regionPos returns the position of the region that the world.ChunkPos passed is in.
+func regionPos(pos world.ChunkPos) world.ChunkPos {
+	return world.ChunkPos{pos[0] >> regionShift, pos[1] >> regionShift}
+}
+
+// cellCache is a cache of the voronoi cells of regions. When the cache is full, the least recently used region is
+// evicted. A cellCache is safe for concurrent use.
+type cellCache struct {
+	size int
+
+	mu      sync.Mutex
+	lru     *list.List
+	entries map[world.ChunkPos]*list.Element
+}
+
+// cacheEntry is an entry in the cellCache. Its cells are computed only once, even if the region is requested by
+// multiple goroutines at the same time.
+type cacheEntry struct {
+	pos   world.ChunkPos
+	once  sync.Once
+	cells *cellIndex
+}
+
+// newCellCache creates a new cellCache that holds the cells of up to size regions.
+func newCellCache(size int) *cellCache {
+	return &cellCache{size: size, lru: list.New(), entries: make(map[world.ChunkPos]*list.Element, size)}
+}
+
+// cells returns the cells of the region at the position passed. If the region is not yet cached, the cells are
+// computed using the function passed and stored.
+func (c *cellCache) cells(region world.ChunkPos, compute func(region world.ChunkPos) *cellIndex) *cellIndex {
+	c.mu.Lock()
+	var entry *cacheEntry
+	if e, ok := c.entries[region]; ok {
+		c.lru.MoveToFront(e)
+		entry = e.Value.(*cacheEntry)
+	} else {
+		entry = &cacheEntry{pos: region}
+		c.entries[region] = c.lru.PushFront(entry)
+		if c.lru.Len() > c.size {
+			oldest := c.lru.Back()
+			c.lru.Remove(oldest)
+			delete(c.entries, oldest.Value.(*cacheEntry).pos)
+		}
+	}
+	c.mu.Unlock()
+
+	// Computing the cells is done without holding the lock, so that other regions may be looked up in the meantime.
+	// Goroutines requesting the same region wait for the first to finish.
+	entry.once.Do(func() {
+		entry.cells = compute(region)
+	})
+	return entry.cells
+}
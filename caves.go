@@ -0,0 +1,277 @@
+package gen
+
+import (
+	"github.com/df-mc/dragonfly/server/world"
+	"github.com/df-mc/dragonfly/server/world/chunk"
+	"github.com/df-mc/gen/f"
+	"github.com/ojrac/opensimplex-go"
+	"math"
+	"math/rand"
+)
+
+// CaveBiome is a Biome that changes the caves carved below it. Caves below Biomes that do not implement CaveBiome are
+// carved with a size of 1.
+type CaveBiome interface {
+	Biome
+	// Caves returns the size of caves carved below the Biome. A value of 1 results in caves of the default size,
+	// while bigger values make caves wider and more common. A value of 0 disables caves below the Biome entirely.
+	Caves() float64
+}
+
+const (
+	// caveCellWidth and caveCellHeight are the horizontal and vertical distance between the positions at which cave
+	// noise is sampled. Noise values between these positions are interpolated.
+	caveCellWidth, caveCellHeight = 4, 4
+	// maxCaveSize is the maximum cave size of a CaveBiome that worm tunnels are carved with.
+	maxCaveSize = 2
+	// wormRange is the maximum distance in chunks that a worm tunnel can travel from the chunk it started in.
+	wormRange = 8
+	// cheeseCeiling is the minimum depth below the surface of cheese caves, so that the large caverns they form do
+	// not tear open the surface.
+	cheeseCeiling = 12
+	// seabedCeiling is the minimum depth below the surface of any cave carved below water, so that the seabed is not
+	// broken.
+	seabedCeiling = 8
+)
+
+// caveCarver carves caves into chunks after their terrain has been placed. It carves three kinds of noise caves:
+// large cheese caves, long spaghetti tunnels and thin noodle caves, and worm tunnels that are carved by moving a
+// sphere along a random path, similar to the caves found in older versions of vanilla.
+// Noise caves are continuous over chunk borders as they are purely a function of the position in the world. Worm
+// tunnels may start up to wormRange chunks away from the chunk being carved: The path of every tunnel that could
+// reach the chunk is recomputed from the seed, and only the parts within the chunk are carved.
+type caveCarver struct {
+	seed                   int64
+	cheese                 f.F3
+	spaghettiA, spaghettiB f.F3
+	noodleA, noodleB       f.F3
+	noodleGate             f.F3
+}
+
+// newCaveCarver creates a new caveCarver with noise derived from the seed passed.
+func newCaveCarver(seed int64) *caveCarver {
+	return &caveCarver{
+		seed:       seed,
+		cheese:     newNoise3(seed+0x1000, 1.0/64, 1.6),
+		spaghettiA: newNoise3(seed+0x2000, 1.0/48, 1.2),
+		spaghettiB: newNoise3(seed+0x3000, 1.0/48, 1.2),
+		noodleA:    newNoise3(seed+0x4000, 1.0/24, 1),
+		noodleB:    newNoise3(seed+0x5000, 1.0/24, 1),
+		noodleGate: newNoise3(seed+0x6000, 1.0/96, 1),
+	}
+}
+
+// carve carves caves into the chunk.Chunk at the world.ChunkPos passed. The terrainMap and heights passed hold the
+// Biome and the height of the surface for every column in the chunk.
+func (cc *caveCarver) carve(pos world.ChunkPos, c *chunk.Chunk, m terrainMap, heights [256]int, s Strata, seaLevel int) {
+	var sizes [256]float64
+	var ceilings [256]int
+	maxY := c.Range().Min()
+	for i, col := range m[:256] {
+		sizes[i] = caveSize(col.biome)
+		ceilings[i] = heights[i]
+		if heights[i] < seaLevel {
+			ceilings[i] -= seabedCeiling
+		}
+		if ceilings[i] > maxY {
+			maxY = ceilings[i]
+		}
+	}
+	cc.carveNoise(pos, c, sizes, ceilings, maxY, s)
+	cc.carveWorms(pos, c, sizes, ceilings, seaLevel, s)
+}
+
+// carveNoise carves the noise caves into a chunk.Chunk. The noise is sampled on a grid of caveCellWidth by
+// caveCellHeight and interpolated in between, which is considerably cheaper than sampling it for every block.
+func (cc *caveCarver) carveNoise(pos world.ChunkPos, c *chunk.Chunk, sizes [256]float64, ceilings [256]int, maxY int, s Strata) {
+	minY := c.Range().Min() + s.BedrockThickness
+	if maxY < minY {
+		return
+	}
+	baseX, baseZ := float64(pos[0]<<4), float64(pos[1]<<4)
+	cellsY := (maxY-minY)/caveCellHeight + 2
+
+	grids := [...]*noiseGrid{
+		newNoiseGrid(cc.cheese, baseX, float64(minY), baseZ, cellsY),
+		newNoiseGrid(cc.spaghettiA, baseX, float64(minY), baseZ, cellsY),
+		newNoiseGrid(cc.spaghettiB, baseX, float64(minY), baseZ, cellsY),
+		newNoiseGrid(cc.noodleA, baseX, float64(minY), baseZ, cellsY),
+		newNoiseGrid(cc.noodleB, baseX, float64(minY), baseZ, cellsY),
+		newNoiseGrid(cc.noodleGate, baseX, float64(minY), baseZ, cellsY),
+	}
+	for x := 0; x < 16; x++ {
+		for z := 0; z < 16; z++ {
+			size, ceiling := sizes[x+z*16], ceilings[x+z*16]
+			if size == 0 {
+				continue
+			}
+			for y := minY; y <= ceiling; y++ {
+				dy := y - minY
+				if y <= ceiling-cheeseCeiling && grids[0].at(x, dy, z)*size > 0.55 {
+					carveBlock(c, x, y, z, s)
+					continue
+				}
+				a, b := grids[1].at(x, dy, z), grids[2].at(x, dy, z)
+				if w := 0.06 * size; a*a+b*b < w*w {
+					carveBlock(c, x, y, z, s)
+					continue
+				}
+				if grids[5].at(x, dy, z) > 0.1 {
+					a, b = grids[3].at(x, dy, z), grids[4].at(x, dy, z)
+					if w := 0.04 * size; a*a+b*b < w*w {
+						carveBlock(c, x, y, z, s)
+					}
+				}
+			}
+		}
+	}
+}
+
+// carveWorms carves the parts of worm tunnels that pass through a chunk.Chunk. Every chunk within wormRange chunks
+// of the chunk passed may start a tunnel, of which the path is computed from the seed and the position of the chunk
+// that it starts in, so that the same tunnels are carved regardless of the order in which chunks are generated.
+// Tunnels start between the bottom of the world and the sea level passed.
+func (cc *caveCarver) carveWorms(pos world.ChunkPos, c *chunk.Chunk, sizes [256]float64, ceilings [256]int, seaLevel int, s Strata) {
+	r := rand.New(new(splitMix))
+	minY := c.Range().Min() + s.BedrockThickness
+	minX, minZ := float64(pos[0]<<4), float64(pos[1]<<4)
+
+	for cx := pos[0] - wormRange; cx <= pos[0]+wormRange; cx++ {
+		for cz := pos[1] - wormRange; cz <= pos[1]+wormRange; cz++ {
+			r.Seed(chunkHash(cc.seed^0x5eed, world.ChunkPos{cx, cz}))
+			if r.Intn(7) != 0 {
+				continue
+			}
+			x, z := float64(cx<<4+r.Int31n(16)), float64(cz<<4+r.Int31n(16))
+			y := float64(minY + r.Intn(maxInt(seaLevel-minY, 1)))
+			yaw, pitch := r.Float64()*math.Pi*2, (r.Float64()-0.5)*0.5
+			width := 1 + r.Float64()*2
+			length := 80 + r.Intn(40)
+
+			var dYaw, dPitch float64
+			for step := 0; step < length; step++ {
+				radius := 1.5 + math.Sin(float64(step)*math.Pi/float64(length))*width
+
+				x += math.Cos(yaw) * math.Cos(pitch)
+				y += math.Sin(pitch)
+				z += math.Sin(yaw) * math.Cos(pitch)
+
+				pitch = pitch*0.7 + dPitch*0.1
+				yaw += dYaw * 0.1
+				dPitch = dPitch*0.9 + (r.Float64()-r.Float64())*r.Float64()*2
+				dYaw = dYaw*0.75 + (r.Float64()-r.Float64())*r.Float64()*4
+
+				if x+radius*maxCaveSize < minX || x-radius*maxCaveSize >= minX+16 || z+radius*maxCaveSize < minZ || z-radius*maxCaveSize >= minZ+16 {
+					// This part of the tunnel does not pass through the chunk: No need to carve anything.
+					continue
+				}
+				carveSphere(c, x-minX, y, z-minZ, radius, sizes, ceilings, s)
+			}
+		}
+	}
+}
+
+// carveSphere carves a sphere with its centre at x, y, z relative to the chunk.Chunk passed. The radius is multiplied
+// by the cave size of every column, up to a maximum of maxCaveSize.
+func carveSphere(c *chunk.Chunk, x, y, z, radius float64, sizes [256]float64, ceilings [256]int, s Strata) {
+	minY := c.Range().Min() + s.BedrockThickness
+	for bx := maxInt(int(math.Floor(x-radius*maxCaveSize)), 0); bx <= minInt(int(x+radius*maxCaveSize), 15); bx++ {
+		for bz := maxInt(int(math.Floor(z-radius*maxCaveSize)), 0); bz <= minInt(int(z+radius*maxCaveSize), 15); bz++ {
+			size, ceiling := math.Min(sizes[bx+bz*16], maxCaveSize), ceilings[bx+bz*16]
+			rad := radius * size
+			for by := maxInt(int(math.Floor(y-rad)), minY); by <= minInt(int(math.Floor(y+rad)), ceiling); by++ {
+				dx, dy, dz := float64(bx)+0.5-x, float64(by)+0.5-y, float64(bz)+0.5-z
+				if dx*dx+dy*dy+dz*dz < rad*rad {
+					carveBlock(c, bx, by, bz, s)
+				}
+			}
+		}
+	}
+}
+
+// carveBlock carves out the block at a position in a chunk.Chunk. Air, water and bedrock are never carved. Blocks at
+// or below the lava level of the Strata passed are replaced with lava instead of air.
+func carveBlock(c *chunk.Chunk, x, y, z int, s Strata) {
+	switch c.Block(uint8(x), int16(y), uint8(z), 0) {
+	case air, water, lava, bedrock:
+		return
+	}
+	if y <= s.LavaLevel {
+		c.SetBlock(uint8(x), int16(y), uint8(z), 0, lava)
+		return
+	}
+	c.SetBlock(uint8(x), int16(y), uint8(z), 0, air)
+}
+
+// caveSize returns the size of caves below a Biome.
+func caveSize(b Biome) float64 {
+	if cb, ok := b.(CaveBiome); ok {
+		return cb.Caves()
+	}
+	return 1
+}
+
+// newNoise3 creates an f.F3 returning noise in the range (-1 1) with the seed and frequency passed. The frequency on the Y axis is multiplied by yScale,
+// so that caves may be stretched horizontally.
+func newNoise3(seed int64, freq, yScale float64) f.F3 {
+	n := opensimplex.New(seed)
+	return func(x, y, z float64) float64 {
+		return n.Eval3(x*freq, y*freq*yScale, z*freq)
+	}
+}
+
+// noiseGrid holds the values of an f.F3 sampled for a chunk on a grid of caveCellWidth by caveCellHeight.
+type noiseGrid struct {
+	cellsY int
+	values []float64
+}
+
+// newNoiseGrid samples the f.F3 passed on a grid starting at x, y, z for a chunk with cellsY vertical cells.
+func newNoiseGrid(n f.F3, x, y, z float64, cellsY int) *noiseGrid {
+	const cellsXZ = 16/caveCellWidth + 1
+	g := &noiseGrid{cellsY: cellsY, values: make([]float64, cellsXZ*cellsXZ*cellsY)}
+	for cx := 0; cx < cellsXZ; cx++ {
+		for cz := 0; cz < cellsXZ; cz++ {
+			for cy := 0; cy < cellsY; cy++ {
+				g.values[(cx*cellsXZ+cz)*cellsY+cy] = n(x+float64(cx*caveCellWidth), y+float64(cy*caveCellHeight), z+float64(cz*caveCellWidth))
+			}
+		}
+	}
+	return g
+}
+
+// at returns the interpolated noise value at a position relative to the start of the noiseGrid.
+func (g *noiseGrid) at(x, y, z int) float64 {
+	const cellsXZ = 16/caveCellWidth + 1
+	cx, cy, cz := x/caveCellWidth, y/caveCellHeight, z/caveCellWidth
+	tx := float64(x%caveCellWidth) / caveCellWidth
+	ty := float64(y%caveCellHeight) / caveCellHeight
+	tz := float64(z%caveCellWidth) / caveCellWidth
+
+	v := func(cx, cz, cy int) float64 {
+		return g.values[(cx*cellsXZ+cz)*g.cellsY+cy]
+	}
+	return lerp(tz,
+		lerp(tx, lerp(ty, v(cx, cz, cy), v(cx, cz, cy+1)), lerp(ty, v(cx+1, cz, cy), v(cx+1, cz, cy+1))),
+		lerp(tx, lerp(ty, v(cx, cz+1, cy), v(cx, cz+1, cy+1)), lerp(ty, v(cx+1, cz+1, cy), v(cx+1, cz+1, cy+1))),
+	)
+}
+
+// lerp linearly interpolates between a and b using t.
+func lerp(t, a, b float64) float64 {
+	return a + (b-a)*t
+}
+
+func maxInt(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
+
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
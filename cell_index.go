@@ -0,0 +1,77 @@
+package gen
+
+import (
+	"github.com/fogleman/delaunay"
+)
+
+// cellIndex is an index of voronoi cells used to quickly find the cell that a position is in. Because a voronoi cell
+// consists of all positions that are closer to its site than to any other site, the cell that a position is in is
+// the cell with the site nearest to it. The nearest site is found by walking over the edges of the
+// delaunay.Triangulation that the cells were created from, always moving to the neighbouring site closest to the
+// position, which is guaranteed to end at the nearest site. When looking up positions close to each other, starting
+// the walk at the site found for the previous position means only a few steps are needed, regardless of the amount of
+// cells in the index.
+type cellIndex struct {
+	cells []cell
+	// sites holds the sites of the cells, indexed by the index of the point in the triangulation.
+	sites []delaunay.Point
+	// neighbours holds the indices of the sites that each site is connected to in the triangulation.
+	neighbours [][]int
+	// cellOf maps the index of a site to the index of its cell in cells.
+	cellOf []int
+}
+
+// newCellIndex creates a cellIndex for the delaunay.Triangulation and the voronoi cells created from it passed.
+func newCellIndex(d *delaunay.Triangulation, cells []cell) *cellIndex {
+	i := &cellIndex{
+		cells:      cells,
+		sites:      d.Points,
+		neighbours: make([][]int, len(d.Points)),
+		cellOf:     make([]int, len(d.Points)),
+	}
+	for e, p := range d.Triangles {
+		q := d.Triangles[nextHalfEdge(e)]
+		i.neighbours[p] = append(i.neighbours[p], q)
+		if d.Halfedges[e] == -1 {
+			// Edges on the hull of the triangulation have no opposite half-edge, so we need to add the connection
+			// in the other direction ourselves.
+			i.neighbours[q] = append(i.neighbours[q], p)
+		}
+	}
+	for n, c := range cells {
+		i.cellOf[c.index] = n
+	}
+	return i
+}
+
+// nearest returns the cell with the site nearest to the point passed, which is the cell that the point is in. The
+// walk to the nearest site is started at the site with the index passed, which should be the site returned by a
+// previous call for a point close to v. The index of the site found is returned so that it may be passed to the next
+// call. If the cellIndex holds no cells, false is returned.
+func (i *cellIndex) nearest(v delaunay.Point, start int) (cell, int, bool) {
+	if i == nil || len(i.cells) == 0 {
+		return cell{}, 0, false
+	}
+	if start < 0 || start >= len(i.sites) {
+		start = 0
+	}
+	current, dist := start, sqDist(i.sites[start], v)
+	for {
+		next := current
+		for _, n := range i.neighbours[current] {
+			if d := sqDist(i.sites[n], v); d < dist {
+				next, dist = n, d
+			}
+		}
+		if next == current {
+			return i.cells[i.cellOf[current]], current, true
+		}
+		current = next
+	}
+}
+
+// sqDist returns the squared distance between two points.
+func sqDist(a, b delaunay.Point) float64 {
+	dx, dy := a.X-b.X, a.Y-b.Y
+	return dx*dx + dy*dy
+}
@@ -0,0 +1,117 @@
+package gen
+
+import (
+	"github.com/df-mc/dragonfly/server/world"
+	"github.com/fogleman/delaunay"
+	"math"
+	"math/rand"
+	"testing"
+)
+
+// testCellIndex creates a cellIndex over the chunks from -20, -20 to 20, 20 with the default point density.
+func testCellIndex(tb testing.TB) (*delaunay.Triangulation, *cellIndex) {
+	d, err := triangulate(1, world.ChunkPos{-20, -20}, world.ChunkPos{20, 20}, 0.06)
+	if err != nil {
+		tb.Fatalf("triangulate: %v", err)
+	}
+	return d, newCellIndex(d, voronoiCells(d))
+}
+
+// bruteForceNearest returns the squared distance from v to the site nearest to it.
+func bruteForceNearest(sites []delaunay.Point, v delaunay.Point) float64 {
+	dist := math.Inf(1)
+	for _, s := range sites {
+		dist = math.Min(dist, sqDist(s, v))
+	}
+	return dist
+}
+
+func TestCellIndexNearest(t *testing.T) {
+	d, index := testCellIndex(t)
+	r := rand.New(rand.NewSource(1))
+
+	check := func(v delaunay.Point, start int) int {
+		ce, site, ok := index.nearest(v, start)
+		if !ok {
+			t.Fatalf("nearest(%v): no cell found", v)
+		}
+		if ce.index != site {
+			t.Fatalf("nearest(%v): cell of site %v returned for site %v", v, ce.index, site)
+		}
+		// Positions on the edge between two cells are as close to both sites, so we compare distances rather than
+		// sites.
+		if got, want := sqDist(d.Points[site], v), bruteForceNearest(d.Points, v); got != want {
+			t.Fatalf("nearest(%v): site at squared distance %v, nearest site at %v", v, got, want)
+		}
+		return site
+	}
+
+	// Random points, with the walk started at a random site and at the site of the previous point.
+	var site int
+	for i := 0; i < 5000; i++ {
+		v := delaunay.Point{X: r.Float64()*640 - 320, Y: r.Float64()*640 - 320}
+		check(v, r.Intn(len(d.Points)))
+		site = check(v, site)
+	}
+	// Points on and just beside the edges between neighbouring cells.
+	for s, neighbours := range index.neighbours {
+		for _, n := range neighbours {
+			a, b := d.Points[s], d.Points[n]
+			mid := delaunay.Point{X: (a.X + b.X) / 2, Y: (a.Y + b.Y) / 2}
+			for _, offset := range []float64{-1e-9, 0, 1e-9} {
+				check(delaunay.Point{X: mid.X + (b.X-a.X)*offset, Y: mid.Y + (b.Y-a.Y)*offset}, s)
+			}
+		}
+	}
+}
+
+// insideCell checks if v is inside the cell passed. It is the test of the linear scan that nearest replaced.
+func insideCell(c cell, v delaunay.Point) bool {
+	l := len(c.corners) - 1
+	for i, a := range c.corners {
+		b := c.corners[l]
+		if i != 0 {
+			b = c.corners[i-1]
+		}
+		if (b.X-a.X)*(v.Y-a.Y)-(b.Y-a.Y)*(v.X-a.X) > 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// BenchmarkCellLookup compares looking up the cells of the 36x36 columns around a chunk with a linear scan over all
+// cells to looking them up with cellIndex.nearest, for a chunk at the centre of the cells and one away from it.
+func BenchmarkCellLookup(b *testing.B) {
+	_, index := testCellIndex(b)
+	for _, bench := range []struct {
+		name   string
+		baseX  float64
+		linear bool
+	}{
+		{"LinearCentre", 0, true},
+		{"LinearEdge", 200, true},
+		{"NearestCentre", 0, false},
+		{"NearestEdge", 200, false},
+	} {
+		b.Run(bench.name, func(b *testing.B) {
+			for n := 0; n < b.N; n++ {
+				var site int
+				for x := -10.0; x < 26; x++ {
+					for z := -10.0; z < 26; z++ {
+						v := delaunay.Point{X: bench.baseX + x, Y: bench.baseX + z}
+						if !bench.linear {
+							_, site, _ = index.nearest(v, site)
+							continue
+						}
+						for _, c := range index.cells {
+							if insideCell(c, v) {
+								break
+							}
+						}
+					}
+				}
+			}
+		})
+	}
+}
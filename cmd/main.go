@@ -11,6 +11,7 @@ import (
 	"log"
 	"net/http"
 	"os"
+	"time"
 )
 
 import _ "net/http/pprof"
@@ -30,12 +31,17 @@ func main() {
 		log.Fatalln(err)
 	}
 
+	g, err := newGenerator(log)
+	if err != nil {
+		log.Fatalln(err)
+	}
+
 	srv := server.New(&config, log)
 	srv.CloseOnProgramEnd()
 	if err := srv.Start(); err != nil {
 		log.Fatalln(err)
 	}
-	srv.World().Generator(gen.New())
+	srv.World().Generator(g)
 	srv.World().ReadOnly()
 	srv.World().SetTime(5000)
 	srv.World().StopTime()
@@ -47,6 +53,19 @@ func main() {
 	}
 }
 
+// newGenerator creates the generator used to generate the world. If a biomes directory exists, the biome definitions in
+// it are loaded on top of the default biomes.
+func newGenerator(log *logrus.Logger) (*gen.Generator, error) {
+	conf := gen.Config{Seed: time.Now().Unix(), Log: log}
+	if _, err := os.Stat("biomes"); err == nil {
+		conf.Biomes = gen.DefaultBiomes(conf)
+		if err := conf.Biomes.LoadDir("biomes", conf); err != nil {
+			return nil, err
+		}
+	}
+	return gen.NewWithConfig(conf), nil
+}
+
 // readConfig reads the configuration from the config.toml file, or creates the file if it does not yet exist.
 func readConfig() (server.Config, error) {
 	c := server.DefaultConfig()
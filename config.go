@@ -0,0 +1,150 @@
+package gen
+
+import (
+	"github.com/df-mc/dragonfly/server/block/cube"
+	"github.com/df-mc/dragonfly/server/world"
+	"github.com/df-mc/gen/f"
+	"github.com/sirupsen/logrus"
+	"runtime"
+)
+
+// Config holds the settings used to create a Generator. Zero values are replaced with their defaults when the Config
+// is passed to NewWithConfig.
+type Config struct {
+	// Log is the Logger used to report problems that the Generator encountered and recovered from during
+	// generation. If nil, Log is set to logrus.New().
+	Log Logger
+	// Seed is the seed that all noise and randomness used by the Generator is derived from. Generators with the same
+	// Seed and Config produce the same terrain.
+	Seed int64
+	// TriangulationRadius is the amount of chunks that the triangulation that voronoi cells (and with that, biomes) are
+	// computed from extends beyond the region of chunks that it is computed for. A bigger radius results in more
+	// accurately aligning cells in neighbouring regions. Defaults to 20.
+	TriangulationRadius int32
+	// PointDensity is a value [0-1) that specifies the likelihood that a chunk contains the node of a voronoi cell. A
+	// higher density results in smaller biomes. Defaults to 0.06.
+	PointDensity float64
+	// CacheSize is the maximum amount of regions of 32x32 chunks of which the voronoi cells are cached. Chunks in the
+	// same region share the same cells, so a bigger cache means cells have to be computed less often when many chunks
+	// are generated at once. Defaults to 64.
+	CacheSize int
+	// Workers is the amount of goroutines that generate chunks in parallel in Generator.GenerateRegion. Defaults to
+	// runtime.NumCPU().
+	Workers int
+	// Range is the vertical range of the chunks created by Generator.GenerateRegion. Defaults to the range of
+	// world.Overworld.
+	Range cube.Range
+	// SmoothingRadius is the radius in blocks around a column that influences the final height of that column. Higher
+	// values result in smoother transitions between biomes. Defaults to 10.
+	SmoothingRadius int
+	// BlurStrength specifies how far, in blocks, the edges of voronoi cells are distorted to obtain less straight
+	// biome edges. Defaults to 50.
+	BlurStrength float64
+	// DisableBlur disables the distortion of the edges of voronoi cells, so that biomes have straight edges. If true,
+	// BlurStrength is set to 0.
+	DisableBlur bool
+	// ClimateFrequency is the frequency of the noise used to select a biome for a voronoi cell. Lower values result in
+	// larger areas with a similar climate. Defaults to 0.05.
+	ClimateFrequency float64
+	// SeaLevel is the Y level up to which air between the terrain surface and the sea level is filled with water.
+	// Defaults to 62, unless SeaLevelSet is true.
+	SeaLevel int
+	// SeaLevelSet specifies that SeaLevel was set explicitly, so that a SeaLevel of 0 is used as is rather than
+	// replaced with its default.
+	SeaLevelSet bool
+	// BaseElevation is the Y level that a Biome height of 0 is mapped to. Defaults to 44, unless BaseElevationSet is
+	// true.
+	BaseElevation int
+	// BaseElevationSet specifies that BaseElevation was set explicitly, so that a BaseElevation of 0 is used as is
+	// rather than replaced with its default.
+	BaseElevationSet bool
+	// Amplitude is the amount of blocks that the terrain rises above BaseElevation for a Biome height of 1. Heights
+	// are clamped to the vertical range of the chunk generated. Defaults to 192.
+	Amplitude float64
+	// Biomes is the BiomeRegistry holding the biomes that are selected from based on the climate. If nil or empty,
+	// Biomes is set to DefaultBiomes(conf).
+	Biomes *BiomeRegistry
+	// Strata holds the Strata used for chunks with a specific cube.Range, so that the layering of rock may be changed
+	// per dimension. If no Strata is present for the range of a chunk, DefaultStrata is used.
+	Strata map[cube.Range]Strata
+	// Density is a density function that, if set, makes the Generator generate terrain in density mode: A block is
+	// solid if the density at its position is bigger than 0, which allows overhangs, arches and floating islands.
+	// The density at a block is the value returned by Density, which should be in the range [-1, 1], plus the
+	// distance of the block below the height of the Biome at its x and z divided by DensityFalloff. If nil, every
+	// block below the height of the Biome is solid.
+	Density f.F3
+	// DensityFalloff is the distance in blocks above and below the height of a Biome within which Density may make
+	// blocks solid or hollow. Higher values result in more pronounced overhangs. Defaults to 24.
+	DensityFalloff float64
+	// DisableCaves disables the carving of caves after the terrain of a chunk has been placed.
+	DisableCaves bool
+	// Ores holds the ores that are placed underground after caves have been carved. Ores are placed in the order
+	// that they are in, so an ore may replace the blocks placed by an ore before it. If nil, Ores is set to
+	// VanillaOres(). Ore placement may be disabled by setting Ores to an empty, non-nil slice.
+	Ores []Ore
+	// Debug specifies if the voronoi diagram that biomes are selected from should be drawn in the sky of every chunk
+	// generated. The edges of every cell are drawn in wool with a colour depending on the Biome selected for the cell.
+	// The centre of the cell is marked with concrete of the same colour. Debug should not be used in production.
+	Debug bool
+	// DebugHeight is the Y level at which the voronoi diagram is drawn if Debug is true. Defaults to 250.
+	DebugHeight int
+}
+
+// withDefaults returns a copy of the Config with all zero values replaced with their default values.
+func (conf Config) withDefaults() Config {
+	if conf.Log == nil {
+		conf.Log = logrus.New()
+	}
+	if conf.TriangulationRadius <= 0 {
+		conf.TriangulationRadius = 20
+	}
+	if conf.PointDensity <= 0 {
+		conf.PointDensity = 0.06
+	}
+	if conf.CacheSize <= 0 {
+		conf.CacheSize = 64
+	}
+	if conf.Workers <= 0 {
+		conf.Workers = runtime.NumCPU()
+	}
+	if conf.Range == (cube.Range{}) {
+		conf.Range = world.Overworld.Range()
+	}
+	if conf.SmoothingRadius <= 0 {
+		conf.SmoothingRadius = 10
+	}
+	if conf.DisableBlur {
+		conf.BlurStrength = 0
+	} else if conf.BlurStrength == 0 {
+		conf.BlurStrength = 50
+	}
+	if conf.ClimateFrequency <= 0 {
+		conf.ClimateFrequency = 0.05
+	}
+	if conf.SeaLevel == 0 && !conf.SeaLevelSet {
+		conf.SeaLevel = 62
+	}
+	if conf.BaseElevation == 0 && !conf.BaseElevationSet {
+		conf.BaseElevation = 44
+	}
+	if conf.Amplitude == 0 {
+		conf.Amplitude = 192
+	}
+	if conf.DensityFalloff <= 0 {
+		conf.DensityFalloff = 24
+	}
+	if conf.Ores == nil {
+		conf.Ores = VanillaOres()
+	}
+	if conf.DebugHeight == 0 {
+		conf.DebugHeight = 250
+	}
+	return conf
+}
+
+// Logger is a logger implementation that may be passed to the Log field of Config. Generator reports problems that it
+// encountered and recovered from, such as an invalid triangulation, to the Logger.
+type Logger interface {
+	Debugf(format string, v ...interface{})
+	Errorf(format string, v ...interface{})
+}
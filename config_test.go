@@ -0,0 +1,32 @@
+package gen
+
+import "testing"
+
+func TestConfigDefaults(t *testing.T) {
+	for _, test := range []struct {
+		name  string
+		conf  Config
+		check func(conf Config) interface{}
+		want  interface{}
+	}{
+		{"SeaLevelDefault", Config{}, func(conf Config) interface{} { return conf.SeaLevel }, 62},
+		{"SeaLevel", Config{SeaLevel: 40}, func(conf Config) interface{} { return conf.SeaLevel }, 40},
+		{"SeaLevelZero", Config{SeaLevelSet: true}, func(conf Config) interface{} { return conf.SeaLevel }, 0},
+		{"BaseElevationDefault", Config{}, func(conf Config) interface{} { return conf.BaseElevation }, 44},
+		{"BaseElevation", Config{BaseElevation: -20}, func(conf Config) interface{} { return conf.BaseElevation }, -20},
+		{"BaseElevationZero", Config{BaseElevationSet: true}, func(conf Config) interface{} { return conf.BaseElevation }, 0},
+		{"BlurStrengthDefault", Config{}, func(conf Config) interface{} { return conf.BlurStrength }, 50.0},
+		{"BlurStrength", Config{BlurStrength: 10}, func(conf Config) interface{} { return conf.BlurStrength }, 10.0},
+		{"DisableBlur", Config{BlurStrength: 10, DisableBlur: true}, func(conf Config) interface{} { return conf.BlurStrength }, 0.0},
+	} {
+		// withDefaults is applied more than once, for example by both NewWithConfig and DefaultBiomes, so it must not
+		// change a Config that already has its defaults.
+		conf := test.conf.withDefaults()
+		if got := test.check(conf); got != test.want {
+			t.Errorf("%v: %v, expected %v", test.name, got, test.want)
+		}
+		if got := test.check(conf.withDefaults()); got != test.want {
+			t.Errorf("%v: %v after applying defaults twice, expected %v", test.name, got, test.want)
+		}
+	}
+}
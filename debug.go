@@ -0,0 +1,67 @@
+package gen
+
+import (
+	"github.com/df-mc/dragonfly/server/block"
+	"github.com/df-mc/dragonfly/server/item"
+	"github.com/df-mc/dragonfly/server/world"
+	"github.com/df-mc/dragonfly/server/world/chunk"
+	"github.com/fogleman/delaunay"
+	"math"
+)
+
+// displayDiagram displays the voronoi cells passed in the chunk.Chunk passed by drawing the edges of the cells in the
+// sky. The edges are slightly moved to the inside of every cell, so that both cells sharing an edge are visible.
+func (g *Generator) displayDiagram(pos world.ChunkPos, cells *cellIndex, chunk *chunk.Chunk) {
+	const inset = 1.5
+	baseX, baseZ := pos[0]<<4, pos[1]<<4
+
+	if cells == nil {
+		return
+	}
+	y := g.conf.DebugHeight
+	if r := chunk.Range(); y < r.Min() || y > r.Max() {
+		return
+	}
+	minX, minZ := float64(baseX), float64(baseZ)
+	set := func(p delaunay.Point, rid uint32) {
+		if x, z := math.Floor(p.X-minX), math.Floor(p.Y-minZ); x >= 0 && x < 16 && z >= 0 && z < 16 {
+			chunk.SetBlock(uint8(x), int16(y), uint8(z), 0, rid)
+		}
+	}
+	for _, c := range cells.cells {
+		if !c.intersects(minX, minZ, minX+16, minZ+16) {
+			continue
+		}
+		colour := debugColour(g.cellBiome(c))
+		wool, concrete := world.BlockRuntimeID(block.Wool{Colour: colour}), world.BlockRuntimeID(block.Concrete{Colour: colour})
+
+		centre := c.centre()
+		corners := make([]delaunay.Point, len(c.corners))
+		for i, corner := range c.corners {
+			dx, dy := centre.X-corner.X, centre.Y-corner.Y
+			dist := math.Sqrt(dx*dx + dy*dy)
+			if dist <= inset {
+				corners[i] = centre
+				continue
+			}
+			corners[i] = delaunay.Point{X: corner.X + dx/dist*inset, Y: corner.Y + dy/dist*inset}
+		}
+		for i, a := range corners {
+			iterateLine(a, corners[(i+1)%len(corners)], func(p delaunay.Point) {
+				set(p, wool)
+			})
+		}
+		for x := -1.0; x <= 1; x++ {
+			for z := -1.0; z <= 1; z++ {
+				set(delaunay.Point{X: centre.X + x, Y: centre.Y + z}, concrete)
+			}
+		}
+	}
+}
+
+// debugColour returns the colour used to display cells with a specific Biome in the voronoi diagram drawn when
+// Config.Debug is true.
+func debugColour(b Biome) item.Colour {
+	colours := item.Colours()
+	return colours[b.ID()%uint32(len(colours))]
+}
@@ -0,0 +1,118 @@
+package gen
+
+import (
+	"github.com/df-mc/dragonfly/server/world"
+	"github.com/df-mc/dragonfly/server/world/chunk"
+	"github.com/df-mc/gen/biome"
+	"math/rand"
+)
+
+// DecoratedBiome is a Biome that has features, such as trees and flowers, placed on top of its surface. Biomes that do
+// not implement DecoratedBiome are not decorated.
+type DecoratedBiome interface {
+	Biome
+	// Decorations returns the Decorations placed on the surface of the Biome. For every column, at most one of the
+	// Decorations is selected, in the order that they are returned.
+	Decorations() []biome.Decoration
+}
+
+// decorationMargin is the distance in blocks beyond the borders of a chunk in which features are placed that may
+// extend into the chunk. It must be at least the horizontal radius of the widest feature.
+const decorationMargin = 3
+
+// decorate places the features of the DecoratedBiomes in the terrainMap passed, which must extend decorationMargin
+// columns beyond the borders of the chunk. Features are not placed in columns at or below the sea level.
+// Every column is decorated using a random source seeded with its position, and columns are decorated in the same
+// order for every chunk. Features rooted in neighbouring chunks are therefore placed exactly the same as when that
+// chunk is decorated, so that features crossing chunk borders line up.
+func (g *Generator) decorate(pos world.ChunkPos, c *chunk.Chunk, m terrainMap, seaLevel int) {
+	p := chunkPlacer{c: c, baseX: int(pos[0]) << 4, baseZ: int(pos[1]) << 4}
+	r := rand.New(new(splitMix))
+	maxY := c.Range().Max()
+	// scratch is a chunk in which the surface of the columns that features are rooted in is reproduced, so that
+	// features can check the block they are placed on, even when it is outside the chunk.
+	var scratch *chunk.Chunk
+
+	dx := 16 + decorationMargin*2
+	for z := 0; z < dx; z++ {
+		for x := 0; x < dx; x++ {
+			col := m[x+z*dx]
+			b, ok := col.biome.(DecoratedBiome)
+			if !ok {
+				continue
+			}
+			height := int(col.height)
+			if height <= seaLevel || height >= maxY {
+				continue
+			}
+			absX, absZ := p.baseX+x-decorationMargin, p.baseZ+z-decorationMargin
+			r.Seed(int64(posHash(g.conf.Seed^0xdec, absX, 0, absZ)))
+
+			// Every Decoration takes up a part of the 256 columns of a chunk proportional to its count, so that it is
+			// placed in Count columns per chunk on average.
+			n := r.Float64() * 256
+			for _, d := range b.Decorations() {
+				if n < d.Count {
+					if scratch == nil {
+						scratch = chunk.New(air, c.Range())
+					}
+					root := rootPlacer{chunkPlacer: p, x: absX, y: height, z: absZ, ground: surfaceBlock(b, absX, height, absZ, scratch)}
+					d.Decorator.Decorate(root, absX, height, absZ, r)
+					break
+				}
+				n -= d.Count
+			}
+		}
+	}
+}
+
+// surfaceBlock returns the block that a Biome covers the surface of the column at absX, absZ with, by covering the
+// column at the same height in the scratch chunk passed. The surface block is computed this way, rather than read from
+// the chunk being decorated, so that it is the same for every chunk that a feature rooted in the column is placed in.
+func surfaceBlock(b Biome, absX, height, absZ int, scratch *chunk.Chunk) uint32 {
+	scratch.SetBlock(0, int16(height), 0, 0, stone)
+	b.CoverGround(0, 0, int32(absX), int32(absZ), height, scratch)
+	return scratch.Block(0, int16(height), 0, 0)
+}
+
+// rootPlacer is a chunkPlacer that also returns the surface block of the column that a feature is rooted in, which
+// may be outside the chunk.
+type rootPlacer struct {
+	chunkPlacer
+	x, y, z int
+	ground  uint32
+}
+
+// Block ...
+func (p rootPlacer) Block(x, y, z int) (uint32, bool) {
+	if x == p.x && y == p.y && z == p.z {
+		return p.ground, true
+	}
+	return p.chunkPlacer.Block(x, y, z)
+}
+
+// chunkPlacer is a biome.Placer that places blocks in a chunk.Chunk. Blocks outside the chunk are ignored.
+type chunkPlacer struct {
+	c            *chunk.Chunk
+	baseX, baseZ int
+}
+
+// SetBlock ...
+func (p chunkPlacer) SetBlock(x, y, z int, rid uint32) {
+	if p.inside(x, y, z) {
+		p.c.SetBlock(uint8(x-p.baseX), int16(y), uint8(z-p.baseZ), 0, rid)
+	}
+}
+
+// Block ...
+func (p chunkPlacer) Block(x, y, z int) (uint32, bool) {
+	if !p.inside(x, y, z) {
+		return 0, false
+	}
+	return p.c.Block(uint8(x-p.baseX), int16(y), uint8(z-p.baseZ), 0), true
+}
+
+// inside checks if the position passed is inside the chunk of the chunkPlacer.
+func (p chunkPlacer) inside(x, y, z int) bool {
+	return x >= p.baseX && x < p.baseX+16 && z >= p.baseZ && z < p.baseZ+16 && y >= p.c.Range().Min() && y <= p.c.Range().Max()
+}
@@ -0,0 +1,504 @@
+package gen
+
+import (
+	"fmt"
+	"github.com/df-mc/dragonfly/server/block"
+	"github.com/df-mc/dragonfly/server/world"
+	"github.com/df-mc/dragonfly/server/world/chunk"
+	"github.com/df-mc/gen/biome"
+	"github.com/df-mc/gen/f"
+	"github.com/pelletier/go-toml"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// LoadDir loads the biome definitions of all TOML files in the directory passed and registers the biomes to the
+// BiomeRegistry. Files are loaded in lexical order of their names. The biomes loaded take precedence over the biomes
+// already registered: Loading biomes into a BiomeRegistry returned by DefaultBiomes replaces the default biomes in
+// the ClimateRanges of the biomes loaded, while keeping the default biomes everywhere else.
+// The noise of the biomes is derived from Config.Seed. See BiomeDefinition for the format of a biome definition. If
+// any of the files could not be loaded, an error is returned and the BiomeRegistry is left unchanged.
+func (r *BiomeRegistry) LoadDir(dir string, conf Config) error {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return fmt.Errorf("read biome directory: %w", err)
+	}
+	loaded := NewBiomeRegistry()
+	for _, e := range entries {
+		if e.IsDir() || filepath.Ext(e.Name()) != ".toml" {
+			continue
+		}
+		path := filepath.Join(dir, e.Name())
+		def, err := readBiomeDefinition(path)
+		if err != nil {
+			return err
+		}
+		b, climate, err := def.Build(conf)
+		if err != nil {
+			return fmt.Errorf("build biome %v: %w", path, err)
+		}
+		loaded.Register(b, climate)
+	}
+	r.biomes = append(loaded.biomes, r.biomes...)
+	r.underground = r.underground || loaded.underground
+	return nil
+}
+
+// readBiomeDefinition reads a BiomeDefinition from the TOML file at the path passed. Unknown keys in the file result
+// in an error, so that typos do not go unnoticed.
+func readBiomeDefinition(path string) (BiomeDefinition, error) {
+	var def BiomeDefinition
+	tree, err := toml.LoadFile(path)
+	if err != nil {
+		return def, fmt.Errorf("read biome definition: %w", err)
+	}
+	if err := prepareTree(tree, reflect.TypeOf(def)); err != nil {
+		return def, fmt.Errorf("decode biome definition %v: %w", path, err)
+	}
+	if err := tree.Unmarshal(&def); err != nil {
+		return def, fmt.Errorf("decode biome definition %v: %w", path, err)
+	}
+	return def, nil
+}
+
+// prepareTree prepares a toml.Tree to be unmarshalled into a struct of the reflect.Type passed. go-toml does not
+// convert integers to floats, so integers in the toml.Tree are converted to floats for all float fields, allowing
+// `scale = 1` to be written instead of `scale = 1.0`. prepareTree returns an error if the toml.Tree holds a key that
+// the struct has no field for.
+func prepareTree(tree *toml.Tree, t reflect.Type) error {
+	fields := make(map[string]reflect.Type, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		fields[t.Field(i).Tag.Get("toml")] = t.Field(i).Type
+	}
+	for _, key := range tree.Keys() {
+		ft, ok := fields[key]
+		if !ok {
+			return fmt.Errorf("%v: unknown key %q", tree.GetPosition(key), key)
+		}
+		if ft.Kind() == reflect.Ptr {
+			ft = ft.Elem()
+		}
+		switch v := tree.Get(key).(type) {
+		case int64:
+			if ft.Kind() == reflect.Float64 {
+				tree.Set(key, float64(v))
+			}
+		case []interface{}:
+			if ft.Kind() == reflect.Slice && ft.Elem().Kind() == reflect.Float64 {
+				for i, e := range v {
+					if n, ok := e.(int64); ok {
+						v[i] = float64(n)
+					}
+				}
+			}
+		case []int64:
+			if ft.Kind() == reflect.Slice && ft.Elem().Kind() == reflect.Float64 {
+				floats := make([]float64, len(v))
+				for i, n := range v {
+					floats[i] = float64(n)
+				}
+				tree.Set(key, floats)
+			}
+		case *toml.Tree:
+			if ft.Kind() == reflect.Struct {
+				if err := prepareTree(v, ft); err != nil {
+					return err
+				}
+			}
+		case []*toml.Tree:
+			if ft.Kind() == reflect.Slice && ft.Elem().Kind() == reflect.Struct {
+				for _, sub := range v {
+					if err := prepareTree(sub, ft.Elem()); err != nil {
+						return err
+					}
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// BiomeDefinition is the definition of a biome as found in a TOML file loaded using BiomeRegistry.LoadDir. Zero values
+// are replaced with their defaults when the biome is built. An example of a biome definition:
+//
+//	biome = "meadow"
+//
+//	[surface]
+//	top = "minecraft:grass"
+//	filler = "minecraft:dirt[dirt_type=normal]"
+//	filler_depth = 3
+//
+//	[height]
+//	octaves = 3
+//	scale = 0.1
+//	offset = 0.1
+//
+//	[climate]
+//	continentalness = [0.42, 1.0]
+//	humidity = [0.4, 0.6]
+//	temperature = [0.35, 0.5]
+//
+//	[[decoration]]
+//	type = "flowers"
+//	count = 6
+//	flowers = ["allium", "azure_bluet", "oxeye_daisy"]
+//
+//	[[decoration]]
+//	type = "tree"
+//	wood = "birch"
+//	count = 0.5
+type BiomeDefinition struct {
+	// Biome is the name of the vanilla biome that the biome is written to chunks as, such as "plains" or
+	// "snowy_plains". The vanilla biome determines the colour of grass, foliage, water and sky. Defaults to "plains".
+	Biome string `toml:"biome"`
+	// Surface describes the blocks that cover the ground of the biome.
+	Surface SurfaceDefinition `toml:"surface"`
+	// Height describes the noise that the height of the biome is computed from.
+	Height HeightDefinition `toml:"height"`
+	// Climate describes the ClimateRange in which the biome is selected.
+	Climate ClimateDefinition `toml:"climate"`
+	// Caves is the size of the caves carved below the biome. A value of 0 disables caves. Defaults to 1.
+	Caves *float64 `toml:"caves"`
+	// Decorations holds the features placed on the surface of the biome, in the [[decoration]] tables.
+	Decorations []DecorationDefinition `toml:"decoration"`
+}
+
+// SurfaceDefinition describes the blocks that cover the ground of a biome defined in a BiomeDefinition. Blocks are
+// written as their name, optionally followed by their properties in square brackets, such as
+// "minecraft:sandstone[sand_stone_type=default]".
+// Columns close to or below the sea level are covered with sand, gravel and clay instead.
+type SurfaceDefinition struct {
+	// Top is the block placed at the surface. Defaults to "minecraft:grass".
+	Top string `toml:"top"`
+	// Filler is the block placed in the FillerDepth blocks below the surface. Defaults to
+	// "minecraft:dirt[dirt_type=normal]".
+	Filler string `toml:"filler"`
+	// FillerDepth is the amount of Filler blocks placed below the surface. It may not be negative. Defaults to 2.
+	FillerDepth int `toml:"filler_depth"`
+}
+
+// HeightDefinition describes the height of a biome defined in a BiomeDefinition. The height is computed from layered
+// noise in the range [0-1), multiplied by Scale and added to Offset. Like all biome heights, the height is then
+// multiplied by Config.Amplitude and added to Config.BaseElevation.
+type HeightDefinition struct {
+	// Seed is added to Config.Seed to obtain the seed of the noise.
+	Seed int64 `toml:"seed"`
+	// Octaves, Lacunarity and Persistence are the parameters of the layered noise, as passed to f.Noise. They default
+	// to 3, 2 and 0.5 respectively. Octaves may not be negative.
+	Octaves     int     `toml:"octaves"`
+	Lacunarity  float64 `toml:"lacunarity"`
+	Persistence float64 `toml:"persistence"`
+	// WarpFrequency and Warp are passed to f.F.WarpDomain to warp the domain of the noise. If Warp is 0, the domain
+	// is not warped.
+	WarpFrequency float64 `toml:"warp_frequency"`
+	Warp          float64 `toml:"warp"`
+	// Scale and Offset transform the noise into the height of the biome.
+	Scale  float64 `toml:"scale"`
+	Offset float64 `toml:"offset"`
+	// Expr is an f.Expr, such as "warp(norm(noise(seed, 3, 2, 0.5)), 0.4, 40)", that replaces the layered noise
+	// described by the fields above if set. `seed` in the expression is Config.Seed with Seed added to it.
+	Expr *f.Expr `toml:"expr"`
+}
+
+// ClimateDefinition describes the ClimateRange of a biome defined in a BiomeDefinition. Every parameter is written as
+// an array holding the minimum and maximum of its Interval, such as [0.25, 0.6]. Parameters left out match any value.
+// A biome with a minimum depth above 0, such as depth = [16, 512], is an underground biome, as described in
+// BiomeRegistry.Register.
+type ClimateDefinition struct {
+	Humidity        []float64 `toml:"humidity"`
+	Temperature     []float64 `toml:"temperature"`
+	Continentalness []float64 `toml:"continentalness"`
+	Erosion         []float64 `toml:"erosion"`
+	Weirdness       []float64 `toml:"weirdness"`
+	Depth           []float64 `toml:"depth"`
+}
+
+// DecorationDefinition describes a biome.Decoration of a biome defined in a BiomeDefinition. The fields used depend on
+// the Type of the decoration:
+//
+//	tree:        wood (defaults to "oak"), min_height (defaults to 4), max_height (defaults to 6)
+//	spruce_tree: min_height (defaults to 6), max_height (defaults to 9)
+//	tall_grass:  no fields
+//	fern:        no fields
+//	flowers:     flowers, such as ["dandelion", "blue_orchid", "lily_of_the_valley"]
+//	cactus:      max_height (defaults to 3)
+type DecorationDefinition struct {
+	// Type is the type of the decoration: tree, spruce_tree, tall_grass, fern, flowers or cactus.
+	Type string `toml:"type"`
+	// Count is the average amount of features placed per chunk.
+	Count     float64  `toml:"count"`
+	Wood      string   `toml:"wood"`
+	MinHeight int      `toml:"min_height"`
+	MaxHeight int      `toml:"max_height"`
+	Flowers   []string `toml:"flowers"`
+}
+
+// Build builds the Biome described by the BiomeDefinition, along with the ClimateRange in which it should be selected.
+// The noise of the Biome is derived from Config.Seed.
+func (def BiomeDefinition) Build(conf Config) (Biome, ClimateRange, error) {
+	conf = conf.withDefaults()
+	def = def.withDefaults()
+
+	id, err := biomeID(def.Biome)
+	if err != nil {
+		return nil, ClimateRange{}, err
+	}
+	climate, err := def.Climate.climateRange()
+	if err != nil {
+		return nil, ClimateRange{}, err
+	}
+	top, err := parseBlockState(def.Surface.Top)
+	if err != nil {
+		return nil, ClimateRange{}, fmt.Errorf("surface top: %w", err)
+	}
+	filler, err := parseBlockState(def.Surface.Filler)
+	if err != nil {
+		return nil, ClimateRange{}, fmt.Errorf("surface filler: %w", err)
+	}
+	if def.Surface.FillerDepth < 0 {
+		return nil, ClimateRange{}, fmt.Errorf("surface filler_depth %v is negative", def.Surface.FillerDepth)
+	}
+	if def.Height.Octaves < 1 {
+		return nil, ClimateRange{}, fmt.Errorf("height octaves %v is less than 1", def.Height.Octaves)
+	}
+	decorations := make([]biome.Decoration, 0, len(def.Decorations))
+	for i, d := range def.Decorations {
+		decorator, err := d.decorator()
+		if err != nil {
+			return nil, ClimateRange{}, fmt.Errorf("decoration %v: %w", i, err)
+		}
+		decorations = append(decorations, biome.Decoration{Decorator: decorator, Count: d.Count})
+	}
+
+	n, err := def.Height.noise(conf.Seed)
+	if err != nil {
+		return nil, ClimateRange{}, fmt.Errorf("height: %w", err)
+	}
+	h := def.Height
+	return &biome.Generic{
+		BiomeID: id,
+		Elevation: func(x, z float64) float64 {
+			return n(x, z)*h.Scale + h.Offset
+		},
+		Noise:       n,
+		SeaLevel:    conf.SeaLevel,
+		Top:         top,
+		Filler:      filler,
+		FillerDepth: def.Surface.FillerDepth,
+		CaveSize:    *def.Caves,
+		Features:    decorations,
+	}, climate, nil
+}
+
+// withDefaults returns a copy of the BiomeDefinition with all zero values replaced with their default values.
+func (def BiomeDefinition) withDefaults() BiomeDefinition {
+	if def.Biome == "" {
+		def.Biome = "plains"
+	}
+	if def.Surface.Top == "" {
+		def.Surface.Top = "minecraft:grass"
+	}
+	if def.Surface.Filler == "" {
+		def.Surface.Filler = "minecraft:dirt[dirt_type=normal]"
+	}
+	if def.Surface.FillerDepth == 0 {
+		def.Surface.FillerDepth = 2
+	}
+	if def.Height.Octaves == 0 {
+		def.Height.Octaves = 3
+	}
+	if def.Height.Lacunarity == 0 {
+		def.Height.Lacunarity = 2
+	}
+	if def.Height.Persistence == 0 {
+		def.Height.Persistence = 0.5
+	}
+	if def.Caves == nil {
+		caves := 1.0
+		def.Caves = &caves
+	}
+	return def
+}
+
+// noise returns the noise described by the HeightDefinition, using the seed passed as the base seed.
+func (def HeightDefinition) noise(seed int64) (f.F, error) {
+	if def.Expr != nil {
+		return def.Expr.Compile(seed + def.Seed)
+	}
+	n := f.Noise(seed+def.Seed, def.Octaves, def.Lacunarity, def.Persistence).Norm()
+	if def.Warp != 0 {
+		n = n.WarpDomain(def.WarpFrequency, def.Warp)
+	}
+	return n, nil
+}
+
+// climateRange converts the ClimateDefinition to a ClimateRange.
+func (def ClimateDefinition) climateRange() (ClimateRange, error) {
+	var r ClimateRange
+	for _, p := range []struct {
+		name   string
+		values []float64
+		i      *Interval
+	}{
+		{"humidity", def.Humidity, &r.Humidity},
+		{"temperature", def.Temperature, &r.Temperature},
+		{"continentalness", def.Continentalness, &r.Continentalness},
+		{"erosion", def.Erosion, &r.Erosion},
+		{"weirdness", def.Weirdness, &r.Weirdness},
+		{"depth", def.Depth, &r.Depth},
+	} {
+		switch len(p.values) {
+		case 0:
+		case 2:
+			if p.values[0] >= p.values[1] {
+				return r, fmt.Errorf("climate %v: minimum %v must be less than maximum %v", p.name, p.values[0], p.values[1])
+			}
+			*p.i = Interval{Min: p.values[0], Max: p.values[1]}
+		default:
+			return r, fmt.Errorf("climate %v: expected [min, max], got %v values", p.name, len(p.values))
+		}
+	}
+	return r, nil
+}
+
+// decorator returns the biome.Decorator described by the DecorationDefinition.
+func (def DecorationDefinition) decorator() (biome.Decorator, error) {
+	if def.Count < 0 {
+		return nil, fmt.Errorf("count must not be negative, got %v", def.Count)
+	}
+	switch def.Type {
+	case "tree":
+		wood, err := woodType(def.Wood)
+		if err != nil {
+			return nil, err
+		}
+		minHeight, maxHeight, err := heightRange(def.MinHeight, def.MaxHeight, 4, 6)
+		if err != nil {
+			return nil, err
+		}
+		return biome.Tree{Wood: wood, MinHeight: minHeight, MaxHeight: maxHeight}, nil
+	case "spruce_tree":
+		minHeight, maxHeight, err := heightRange(def.MinHeight, def.MaxHeight, 6, 9)
+		if err != nil {
+			return nil, err
+		}
+		return biome.SpruceTree{MinHeight: minHeight, MaxHeight: maxHeight}, nil
+	case "tall_grass":
+		return biome.TallGrass{}, nil
+	case "fern":
+		return biome.TallGrass{Fern: true}, nil
+	case "flowers":
+		if len(def.Flowers) == 0 {
+			return nil, fmt.Errorf("flowers decoration has no flowers")
+		}
+		types := make([]block.FlowerType, 0, len(def.Flowers))
+		for _, name := range def.Flowers {
+			t, err := flowerType(name)
+			if err != nil {
+				return nil, err
+			}
+			types = append(types, t)
+		}
+		return biome.Flowers{Types: types}, nil
+	case "cactus":
+		_, maxHeight, err := heightRange(1, def.MaxHeight, 1, 3)
+		if err != nil {
+			return nil, err
+		}
+		return biome.Cactus{MaxHeight: maxHeight}, nil
+	}
+	return nil, fmt.Errorf("unknown decoration type %q", def.Type)
+}
+
+// heightRange returns the minimum and maximum height passed, replacing zero values with the defaults passed. An error
+// is returned if both are set and the maximum is lower than the minimum. If only one is set and the maximum ends up
+// lower than the minimum, the minimum is returned as maximum.
+func heightRange(min, max, defaultMin, defaultMax int) (int, int, error) {
+	if min > 0 && max > 0 && max < min {
+		return 0, 0, fmt.Errorf("max_height %v is lower than min_height %v", max, min)
+	}
+	if min <= 0 {
+		min = defaultMin
+	}
+	if max <= 0 {
+		max = defaultMax
+	}
+	if max < min {
+		max = min
+	}
+	return min, max, nil
+}
+
+// biomeID returns the Bedrock biome ID of the vanilla biome with the name passed, such as "plains".
+func biomeID(name string) (uint32, error) {
+	for _, b := range world.Biomes() {
+		if b.String() == name {
+			return uint32(b.EncodeBiome()), nil
+		}
+	}
+	return 0, fmt.Errorf("unknown biome %q", name)
+}
+
+// woodType returns the block.WoodType with the name passed, such as "oak" or "dark_oak". An empty name returns oak.
+func woodType(name string) (block.WoodType, error) {
+	if name == "" {
+		return block.OakWood(), nil
+	}
+	for _, w := range block.WoodTypes() {
+		if w.String() == name {
+			return w, nil
+		}
+	}
+	return block.WoodType{}, fmt.Errorf("unknown wood type %q", name)
+}
+
+// flowerType returns the block.FlowerType with the name passed, such as "blue_orchid" or "lily_of_the_valley".
+func flowerType(name string) (block.FlowerType, error) {
+	for _, t := range block.FlowerTypes() {
+		if strings.ToLower(strings.ReplaceAll(t.Name(), " ", "_")) == name {
+			return t, nil
+		}
+	}
+	return block.FlowerType{}, fmt.Errorf("unknown flower type %q", name)
+}
+
+// parseBlockState parses a block state written as its name, optionally followed by its properties in square
+// brackets, such as "minecraft:snow_layer[height=0,covered_bit=false]", and returns its runtime ID. The "minecraft:"
+// prefix may be left out. Property values are parsed as booleans or integers if possible, and as strings otherwise.
+func parseBlockState(s string) (uint32, error) {
+	name, props := s, map[string]interface{}{}
+	if i := strings.IndexByte(s, '['); i != -1 {
+		if !strings.HasSuffix(s, "]") {
+			return 0, fmt.Errorf("block state %q: missing closing bracket", s)
+		}
+		name = s[:i]
+		for _, prop := range strings.Split(s[i+1:len(s)-1], ",") {
+			if prop = strings.TrimSpace(prop); prop == "" {
+				continue
+			}
+			kv := strings.SplitN(prop, "=", 2)
+			if len(kv) != 2 {
+				return 0, fmt.Errorf("block state %q: property %q has no value", s, prop)
+			}
+			k, v := strings.TrimSpace(kv[0]), strings.TrimSpace(kv[1])
+			if n, err := strconv.ParseInt(v, 10, 32); err == nil {
+				props[k] = int32(n)
+			} else if v == "true" || v == "false" {
+				props[k] = v == "true"
+			} else {
+				props[k] = v
+			}
+		}
+	}
+	if !strings.Contains(name, ":") {
+		name = "minecraft:" + name
+	}
+	rid, ok := chunk.StateToRuntimeID(name, props)
+	if !ok {
+		return 0, fmt.Errorf("unknown block state %q", s)
+	}
+	return rid, nil
+}
@@ -0,0 +1,135 @@
+package gen
+
+import (
+	"github.com/df-mc/dragonfly/server/block"
+	"github.com/df-mc/dragonfly/server/world"
+	"github.com/df-mc/gen/biome"
+	"github.com/df-mc/gen/f"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// writeBiomeDir writes the biome definitions passed to a new temporary directory, with the keys of the map as file
+// names, and returns the path of the directory.
+func writeBiomeDir(t *testing.T, files map[string]string) string {
+	dir := t.TempDir()
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatalf("write %v: %v", name, err)
+		}
+	}
+	return dir
+}
+
+func TestLoadDir(t *testing.T) {
+	dir := writeBiomeDir(t, map[string]string{"meadow.toml": `
+biome = "meadow"
+
+[surface]
+filler = "minecraft:dirt[dirt_type=coarse]"
+
+[height]
+seed = 3
+expr = "warp(norm(noise(seed, 3, 2, 0.5)), 0.4, 40)"
+scale = 0.1
+offset = 1
+
+[climate]
+humidity = [0, 1]
+temperature = [0.35, 0.5]
+
+[[decoration]]
+type = "tree"
+count = 2
+`})
+	conf := Config{Seed: 5}
+	r := NewBiomeRegistry()
+	if err := r.LoadDir(dir, conf); err != nil {
+		t.Fatalf("load biomes: %v", err)
+	}
+	if len(r.biomes) != 1 {
+		t.Fatalf("%v biomes loaded, expected 1", len(r.biomes))
+	}
+	if want := (ClimateRange{Humidity: Interval{0, 1}, Temperature: Interval{0.35, 0.5}}); r.biomes[0].r != want {
+		t.Errorf("climate range %+v loaded, expected %+v", r.biomes[0].r, want)
+	}
+	g, ok := r.biomes[0].b.(*biome.Generic)
+	if !ok {
+		t.Fatalf("biome of type %T loaded, expected *biome.Generic", r.biomes[0].b)
+	}
+	if want := world.BlockRuntimeID(block.Dirt{Coarse: true}); g.Filler != want {
+		t.Errorf("filler %v loaded, expected coarse dirt (%v)", g.Filler, want)
+	}
+
+	// The expression must have been decoded from its text, so the height must be that of the compiled expression.
+	n, err := f.MustParse("warp(norm(noise(seed, 3, 2, 0.5)), 0.4, 40)").Compile(conf.Seed + 3)
+	if err != nil {
+		t.Fatalf("compile expression: %v", err)
+	}
+	for _, pos := range [][2]float64{{0, 0}, {12.5, -40}, {-300, 1000}} {
+		if got, want := g.Height(pos[0], pos[1]), n(pos[0], pos[1])*0.1+1; got != want {
+			t.Errorf("height at %v is %v, expected %v", pos, got, want)
+		}
+	}
+
+	// The integer count must have been converted to a float.
+	if len(g.Features) != 1 {
+		t.Fatalf("%v decorations loaded, expected 1", len(g.Features))
+	}
+	if g.Features[0].Count != 2 {
+		t.Errorf("decoration count %v loaded, expected 2", g.Features[0].Count)
+	}
+	if tree, ok := g.Features[0].Decorator.(biome.Tree); !ok || tree.MinHeight != 4 || tree.MaxHeight != 6 {
+		t.Errorf("decorator %#v loaded, expected oak tree with default heights", g.Features[0].Decorator)
+	}
+}
+
+func TestLoadDirErrors(t *testing.T) {
+	for name, test := range map[string]struct {
+		definition, err string
+	}{
+		"UnknownKey":            {"[height]\noctaves = 3\nscael = 0.1\n", `unknown key "scael"`},
+		"UnknownDecorationType": {"[[decoration]]\ntype = \"mushroom\"\ncount = 1\n", `unknown decoration type "mushroom"`},
+		"InvertedHeights":       {"[[decoration]]\ntype = \"tree\"\nmin_height = 7\nmax_height = 5\n", "max_height 5 is lower than min_height 7"},
+		"UnknownBlock":          {"[surface]\ntop = \"minecraft:grass_but_blue\"\n", `unknown block state "minecraft:grass_but_blue"`},
+		"InvalidExpr":           {"[height]\nexpr = \"noise(seed, 3\"\n", "missing ')'"},
+		"InvalidClimate":        {"[climate]\nhumidity = [0.6, 0.4]\n", "minimum 0.6 must be less than maximum 0.4"},
+		"NegativeOctaves":       {"[height]\noctaves = -1\n", "height octaves -1 is less than 1"},
+		"NegativeFillerDepth":   {"[surface]\nfiller_depth = -2\n", "surface filler_depth -2 is negative"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			dir := writeBiomeDir(t, map[string]string{"a.toml": "", "b.toml": test.definition})
+			r := NewBiomeRegistry()
+			err := r.LoadDir(dir, Config{})
+			if err == nil {
+				t.Fatalf("no error returned, expected error containing %q", test.err)
+			}
+			if !strings.Contains(err.Error(), test.err) {
+				t.Errorf("error %q returned, expected error containing %q", err, test.err)
+			}
+			if len(r.biomes) != 0 {
+				t.Errorf("%v biomes registered after error, expected registry to be unchanged", len(r.biomes))
+			}
+		})
+	}
+}
+
+func TestParseBlockState(t *testing.T) {
+	for _, s := range []string{"minecraft:grass", "grass", "minecraft:dirt[dirt_type=normal]", "minecraft:sandstone[ sand_stone_type = default ]"} {
+		if _, err := parseBlockState(s); err != nil {
+			t.Errorf("parse %q: %v", s, err)
+		}
+	}
+	for s, msg := range map[string]string{
+		"minecraft:dirt[dirt_type=normal": "missing closing bracket",
+		"minecraft:dirt[dirt_type]":       `property "dirt_type" has no value`,
+		"minecraft:dirt[dirt_type=blue]":  "unknown block state",
+		"minecraft:not_a_block":           "unknown block state",
+	} {
+		if _, err := parseBlockState(s); err == nil || !strings.Contains(err.Error(), msg) {
+			t.Errorf("parse %q: error %v returned, expected error containing %q", s, err, msg)
+		}
+	}
+}
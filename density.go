@@ -0,0 +1,53 @@
+package gen
+
+import (
+	"github.com/df-mc/dragonfly/server/block/cube"
+	"github.com/df-mc/dragonfly/server/world"
+	"math"
+)
+
+// solid checks if the block at a position is solid in density mode. The height passed is the height of the surface
+// computed from the Biomes at the x and z of the block.
+func (g *Generator) solid(x, y, z int, height float64) bool {
+	return g.conf.Density(float64(x), float64(y), float64(z))+(height-float64(y))/g.conf.DensityFalloff > 0
+}
+
+// densityBand returns the range of Y values around the height of a surface in which Config.Density determines if a
+// block is solid. Blocks below the band are always solid, blocks above it always hollow.
+func (g *Generator) densityBand(height float64) (minY, maxY int) {
+	return int(math.Floor(height - g.conf.DensityFalloff)), int(math.Ceil(height + g.conf.DensityFalloff))
+}
+
+// top returns the Y of the highest solid block in a column in density mode, clamped to the cube.Range passed.
+func (g *Generator) top(x, z int, height float64, ra cube.Range) int {
+	minY, maxY := g.densityBand(height)
+	if maxY > ra.Max() {
+		maxY = ra.Max()
+	}
+	for y := maxY; y > minY && y > ra.Min(); y-- {
+		if g.solid(x, y, z, height) {
+			return y
+		}
+	}
+	if minY < ra.Min() {
+		return ra.Min()
+	}
+	return minY
+}
+
+// densitySurface returns a copy of the terrainMap passed, which extends margin columns beyond the chunk at the
+// world.ChunkPos passed, with the height of every column replaced with the Y of its highest solid block in density
+// mode.
+func (g *Generator) densitySurface(pos world.ChunkPos, m terrainMap, margin int, ra cube.Range) terrainMap {
+	dx := 16 + margin*2
+	baseX, baseZ := int(pos[0]<<4)-margin, int(pos[1]<<4)-margin
+
+	surface := make(terrainMap, len(m))
+	for z := 0; z < dx; z++ {
+		for x := 0; x < dx; x++ {
+			col := m[x+z*dx]
+			surface[x+z*dx] = terrainColumn{height: float64(g.top(baseX+x, baseZ+z, col.height, ra)), biome: col.biome}
+		}
+	}
+	return surface
+}
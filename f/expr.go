@@ -0,0 +1,532 @@
+package f
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Expr is a declarative expression describing an F. Unlike an F, an Expr may be inspected, saved as text and parsed
+// again. An Expr is written as nested function calls, such as:
+//
+//	warp(norm(noise(seed+1, 3, 2, 0.5)), 0.4, 40)
+//
+// Every function corresponds to a function or method in this package:
+//
+//	noise(seed, octaves, lacunarity, persistence)        Noise
+//	perlin(seed, octaves, lacunarity, persistence)       Perlin
+//	value(seed, octaves, lacunarity, persistence)        Value
+//	worley_f1(seed, octaves, lacunarity, persistence)    Worley with WorleyF1
+//	worley_f2(seed, octaves, lacunarity, persistence)    Worley with WorleyF2
+//	worley_f2f1(seed, octaves, lacunarity, persistence)  Worley with WorleyF2F1
+//	worley_cell(seed, octaves, lacunarity, persistence)  Worley with WorleyCell
+//	ridged(seed, octaves, lacunarity, persistence)       Ridged
+//	billow(seed, octaves, lacunarity, persistence)       Billow
+//	fractal(seed, octaves, lacunarity, persistence)      Fractal with Rotate set to true
+//	sum(f, ...)                                          Sum
+//	norm(f)                                              F.Norm
+//	inv(f)                                               F.Inv
+//	pow(f, v)                                            F.Pow
+//	thresh(f, v)                                         F.Thresh
+//	abs(f)                                               F.Abs
+//	mul(f, v)                                            F.Mul
+//	freq(f, v)                                           F.Freq
+//	mulf(f, g)                                           F.MulF
+//	slope(f, dx)                                         F.Slope
+//	warp(f, freq, warp)                                  F.WarpDomain
+//	spline(f, location, value, derivative, ...)          F.Spline
+//	linear_spline(f, location, value, ...)               F.LinearSpline
+//	add(f, g), sub(f, g), div(f, g)                      F.AddF, F.SubF and F.DivF
+//	min(f, ...), max(f, ...)                             Min and Max
+//	clamp(f, min, max)                                   F.Clamp
+//	lerp(a, b, t)                                        Lerp
+//	select(control, a, b, threshold, falloff)            Select
+//	terrace(f, steps)                                    F.Terrace
+//	translate(f, dx, dy)                                 F.Translate
+//	rotate(f, angle)                                     F.Rotate
+//	scale(f, sx, sy)                                     F.Scale
+//	turbulence(f, seed, freq, power)                     F.Turbulence
+//
+// Where a function is expected, a number may be passed too, which results in a function that always returns that
+// number, like Const. The values of spline points are functions, so that splines may be nested. Seeds are written as
+// `seed`, which is replaced with the seed passed to Compile, optionally followed by an offset, such as `seed+3` or
+// `seed-0x10`. An integer may also be passed as a fixed seed. Numbers must be finite: Infinity and NaN cannot be
+// written.
+type Expr struct {
+	// Func is the name of the function called by the Expr, such as "noise" or "warp". If empty, the Expr is a
+	// constant: Either a number, or the seed if Seed is true.
+	Func string
+	// Args holds the arguments that Func is called with.
+	Args []Expr
+	// Value is the number that a constant Expr evaluates to. If Seed is true, Value is the offset added to the seed.
+	Value float64
+	// Seed specifies if the Expr is the seed passed to Compile, with Value added to it.
+	Seed bool
+}
+
+// Parse parses an Expr from its text representation. An error is returned if the text is not a valid expression.
+// Parse only checks the syntax of the expression: Whether the functions called exist and are passed the right
+// arguments is checked by Expr.Compile.
+func Parse(s string) (Expr, error) {
+	p := &parser{s: s}
+	e, err := p.expr()
+	if err != nil {
+		return Expr{}, fmt.Errorf("f: parse %q: %w", s, err)
+	}
+	if p.skipSpace(); p.pos != len(s) {
+		return Expr{}, fmt.Errorf("f: parse %q: unexpected %q at position %v", s, s[p.pos], p.pos)
+	}
+	return e, nil
+}
+
+// MustParse parses an Expr like Parse, but panics if the text is not a valid expression. It is intended for
+// expressions that are known to be valid, such as constants in the source code.
+func MustParse(s string) Expr {
+	e, err := Parse(s)
+	if err != nil {
+		panic(err)
+	}
+	return e
+}
+
+// String returns the text representation of the Expr, which Parse turns back into the same Expr.
+func (e Expr) String() string {
+	var b strings.Builder
+	e.write(&b)
+	return b.String()
+}
+
+// write writes the text representation of the Expr to the strings.Builder passed.
+func (e Expr) write(b *strings.Builder) {
+	switch {
+	case e.Func != "":
+		b.WriteString(e.Func)
+		b.WriteByte('(')
+		for i, arg := range e.Args {
+			if i != 0 {
+				b.WriteString(", ")
+			}
+			arg.write(b)
+		}
+		b.WriteByte(')')
+	case e.Seed:
+		b.WriteString("seed")
+		if e.Value > 0 {
+			b.WriteByte('+')
+		}
+		if e.Value != 0 {
+			b.WriteString(formatNumber(e.Value))
+		}
+	default:
+		b.WriteString(formatNumber(e.Value))
+	}
+}
+
+// MarshalText encodes the Expr as its text representation.
+func (e Expr) MarshalText() ([]byte, error) {
+	return []byte(e.String()), nil
+}
+
+// UnmarshalText decodes an Expr from its text representation, as Parse does.
+func (e *Expr) UnmarshalText(text []byte) error {
+	parsed, err := Parse(string(text))
+	if err != nil {
+		return err
+	}
+	*e = parsed
+	return nil
+}
+
+// Compile compiles the Expr into an F. Occurrences of `seed` in the Expr are replaced with the seed passed. An error
+// is returned if the Expr calls a function that does not exist, passes invalid arguments to a function or holds a
+// number that is not finite.
+func (e Expr) Compile(seed int64) (F, error) {
+	v, err := e.compile(seed, argF)
+	if err != nil {
+		return nil, fmt.Errorf("f: compile %v: %w", e, err)
+	}
+	return v.f, nil
+}
+
+// formatNumber formats a number so that it is parsed back into exactly the same number.
+func formatNumber(v float64) string {
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+// argKind is the kind of an argument passed to a function in an Expr.
+type argKind int
+
+const (
+	// argF is an argument that is an F. Numbers passed are turned into a constant F.
+	argF argKind = iota
+	// argNum is an argument that is a number.
+	argNum
+	// argInt is an argument that is an integer.
+	argInt
+	// argSeed is an argument that is a seed: Either `seed` with an optional offset, or an integer.
+	argSeed
+)
+
+// String ...
+func (k argKind) String() string {
+	switch k {
+	case argF:
+		return "function"
+	case argNum:
+		return "number"
+	case argInt:
+		return "integer"
+	default:
+		return "seed"
+	}
+}
+
+// value is the value of a compiled argument. Depending on the argKind of the argument, f, n or seed is set.
+type value struct {
+	f    F
+	n    float64
+	seed int64
+}
+
+// function is a function that may be called in an Expr.
+type function struct {
+	// args holds the kinds of the arguments of the function.
+	args []argKind
+	// variadic is the amount of arguments at the end of args that may be repeated any amount of times, as a group.
+	// The arguments must be passed at least once.
+	variadic int
+	// build builds an F from the compiled arguments passed.
+	build func(v []value) F
+	// check, if not nil, checks the compiled arguments passed before they are passed to build, so that build does
+	// not panic.
+	check func(v []value) error
+}
+
+// functions holds all functions that may be called in an Expr, indexed by their name.
+var functions = map[string]function{
+	"noise":       noiseFunction(Noise),
+	"perlin":      noiseFunction(Perlin),
+	"value":       noiseFunction(Value),
+	"worley_f1":   worleyFunction(WorleyF1),
+	"worley_f2":   worleyFunction(WorleyF2),
+	"worley_f2f1": worleyFunction(WorleyF2F1),
+	"worley_cell": worleyFunction(WorleyCell),
+	"ridged":      noiseFunction(Ridged),
+	"billow":      noiseFunction(Billow),
+	"fractal": noiseFunction(func(seed int64, octaves int, lacunarity, persistence float64) F {
+		return Fractal{Seed: seed, Octaves: octaves, Lacunarity: lacunarity, Persistence: persistence, Rotate: true}.F()
+	}),
+	"sum":    {args: []argKind{argF}, variadic: 1, build: func(v []value) F { return Sum(functionArgs(v)...) }},
+	"norm":   {args: []argKind{argF}, build: func(v []value) F { return v[0].f.Norm() }},
+	"inv":    {args: []argKind{argF}, build: func(v []value) F { return v[0].f.Inv() }},
+	"pow":    {args: []argKind{argF, argNum}, build: func(v []value) F { return v[0].f.Pow(v[1].n) }},
+	"thresh": {args: []argKind{argF, argNum}, build: func(v []value) F { return v[0].f.Thresh(v[1].n) }},
+	"abs":    {args: []argKind{argF}, build: func(v []value) F { return v[0].f.Abs() }},
+	"mul":    {args: []argKind{argF, argNum}, build: func(v []value) F { return v[0].f.Mul(v[1].n) }},
+	"freq":   {args: []argKind{argF, argNum}, build: func(v []value) F { return v[0].f.Freq(v[1].n) }},
+	"mulf":   {args: []argKind{argF, argF}, build: func(v []value) F { return v[0].f.MulF(v[1].f) }},
+	"slope":  {args: []argKind{argF, argNum}, build: func(v []value) F { return v[0].f.Slope(v[1].n) }},
+	"warp":   {args: []argKind{argF, argNum, argNum}, build: func(v []value) F { return v[0].f.WarpDomain(v[1].n, v[2].n) }},
+	"spline": {args: []argKind{argF, argNum, argF, argNum}, variadic: 3, build: func(v []value) F {
+		points := make([]SplinePoint, 0, len(v)/3)
+		for i := 1; i < len(v); i += 3 {
+			points = append(points, SplinePoint{Location: v[i].n, Value: v[i+1].f, Derivative: v[i+2].n})
+		}
+		return v[0].f.Spline(points...)
+	}},
+	"linear_spline": {args: []argKind{argF, argNum, argF}, variadic: 2, build: func(v []value) F {
+		points := make([]SplinePoint, 0, len(v)/2)
+		for i := 1; i < len(v); i += 2 {
+			points = append(points, SplinePoint{Location: v[i].n, Value: v[i+1].f})
+		}
+		return v[0].f.LinearSpline(points...)
+	}},
+	"add":   {args: []argKind{argF, argF}, build: func(v []value) F { return v[0].f.AddF(v[1].f) }},
+	"sub":   {args: []argKind{argF, argF}, build: func(v []value) F { return v[0].f.SubF(v[1].f) }},
+	"div":   {args: []argKind{argF, argF}, build: func(v []value) F { return v[0].f.DivF(v[1].f) }},
+	"min":   {args: []argKind{argF}, variadic: 1, build: func(v []value) F { return Min(functionArgs(v)...) }},
+	"max":   {args: []argKind{argF}, variadic: 1, build: func(v []value) F { return Max(functionArgs(v)...) }},
+	"clamp": {args: []argKind{argF, argNum, argNum}, build: func(v []value) F { return v[0].f.Clamp(v[1].n, v[2].n) }},
+	"lerp":  {args: []argKind{argF, argF, argF}, build: func(v []value) F { return Lerp(v[0].f, v[1].f, v[2].f) }},
+	"select": {args: []argKind{argF, argF, argF, argNum, argNum}, build: func(v []value) F {
+		return Select(v[0].f, v[1].f, v[2].f, v[3].n, v[4].n)
+	}},
+	"terrace": {args: []argKind{argF, argInt}, build: func(v []value) F { return v[0].f.Terrace(int(v[1].n)) },
+		check: func(v []value) error {
+			if v[1].n < 1 {
+				return fmt.Errorf("steps %v is less than 1", v[1].n)
+			}
+			return nil
+		},
+	},
+	"translate": {args: []argKind{argF, argNum, argNum}, build: func(v []value) F { return v[0].f.Translate(v[1].n, v[2].n) }},
+	"rotate":    {args: []argKind{argF, argNum}, build: func(v []value) F { return v[0].f.Rotate(v[1].n) }},
+	"scale":     {args: []argKind{argF, argNum, argNum}, build: func(v []value) F { return v[0].f.Scale(v[1].n, v[2].n) }},
+	"turbulence": {args: []argKind{argF, argSeed, argNum, argNum}, build: func(v []value) F {
+		return v[0].f.Turbulence(v[1].seed, v[2].n, v[3].n)
+	}},
+}
+
+// functionArgs returns the functions of the compiled arguments passed.
+func functionArgs(v []value) []F {
+	fs := make([]F, len(v))
+	for i, arg := range v {
+		fs[i] = arg.f
+	}
+	return fs
+}
+
+// noiseFunction returns a function that calls the noise function passed with a seed, octaves, lacunarity and
+// persistence.
+func noiseFunction(noise func(seed int64, octaves int, lacunarity, persistence float64) F) function {
+	return function{args: []argKind{argSeed, argInt, argNum, argNum}, build: func(v []value) F {
+		return noise(v[0].seed, int(v[1].n), v[2].n, v[3].n)
+	}, check: func(v []value) error {
+		if v[1].n < 1 || v[1].n > maxOctaves {
+			return fmt.Errorf("octaves %v is not in the range [1 %v]", v[1].n, maxOctaves)
+		}
+		return nil
+	}}
+}
+
+// maxOctaves is the maximum amount of octaves of noise in an Expr. For any useful persistence, octaves beyond it add
+// nothing noticeable to the noise, while making it slow to compute.
+const maxOctaves = 64
+
+// worleyFunction returns the function that calculates Worley noise with the WorleyMode passed.
+func worleyFunction(mode WorleyMode) function {
+	return noiseFunction(func(seed int64, octaves int, lacunarity, persistence float64) F {
+		return Worley(seed, octaves, lacunarity, persistence, mode)
+	})
+}
+
+// compile compiles the Expr into a value of the argKind passed.
+func (e Expr) compile(seed int64, kind argKind) (value, error) {
+	if e.Func == "" {
+		return e.compileConstant(seed, kind)
+	}
+	if kind != argF {
+		return value{}, fmt.Errorf("expected %v, got %v", kind, e.Func)
+	}
+	fn, ok := functions[e.Func]
+	if !ok {
+		return value{}, fmt.Errorf("unknown function %v", e.Func)
+	}
+	if fn.variadic == 1 && len(e.Args) < len(fn.args) {
+		return value{}, fmt.Errorf("%v: expected at least %v arguments, got %v", e.Func, len(fn.args), len(e.Args))
+	}
+	if fn.variadic > 1 && (len(e.Args) < len(fn.args) || (len(e.Args)-len(fn.args))%fn.variadic != 0) {
+		return value{}, fmt.Errorf("%v: expected %v arguments plus a multiple of %v, got %v", e.Func, len(fn.args), fn.variadic, len(e.Args))
+	}
+	if fn.variadic == 0 && len(e.Args) != len(fn.args) {
+		return value{}, fmt.Errorf("%v: expected %v arguments, got %v", e.Func, len(fn.args), len(e.Args))
+	}
+	values := make([]value, len(e.Args))
+	for i, arg := range e.Args {
+		var argKind argKind
+		if i < len(fn.args) {
+			argKind = fn.args[i]
+		} else {
+			// Arguments beyond args repeat the last variadic arguments.
+			argKind = fn.args[len(fn.args)-fn.variadic+(i-len(fn.args))%fn.variadic]
+		}
+		v, err := arg.compile(seed, argKind)
+		if err != nil {
+			return value{}, fmt.Errorf("%v: argument %v: %w", e.Func, i+1, err)
+		}
+		values[i] = v
+	}
+	if fn.check != nil {
+		if err := fn.check(values); err != nil {
+			return value{}, fmt.Errorf("%v: %w", e.Func, err)
+		}
+	}
+	return value{f: fn.build(values)}, nil
+}
+
+// compileConstant compiles a constant Expr into a value of the argKind passed.
+func (e Expr) compileConstant(seed int64, kind argKind) (value, error) {
+	if math.IsInf(e.Value, 0) || math.IsNaN(e.Value) {
+		return value{}, fmt.Errorf("%v is not a finite number", e.Value)
+	}
+	if e.Seed {
+		if kind != argSeed {
+			return value{}, fmt.Errorf("expected %v, got seed", kind)
+		}
+		if e.Value != math.Trunc(e.Value) {
+			return value{}, fmt.Errorf("seed offset %v is not an integer", e.Value)
+		}
+		if !inInt64Range(e.Value) {
+			return value{}, fmt.Errorf("seed offset %v is out of range", formatNumber(e.Value))
+		}
+		return value{seed: seed + int64(e.Value)}, nil
+	}
+	switch kind {
+	case argF:
+		return value{f: Const(e.Value)}, nil
+	case argInt:
+		if e.Value != math.Trunc(e.Value) {
+			return value{}, fmt.Errorf("expected %v, got %v", kind, formatNumber(e.Value))
+		}
+		if e.Value < math.MinInt32 || e.Value > math.MaxInt32 {
+			return value{}, fmt.Errorf("integer %v is out of range", formatNumber(e.Value))
+		}
+		return value{n: e.Value}, nil
+	case argSeed:
+		if e.Value != math.Trunc(e.Value) {
+			return value{}, fmt.Errorf("expected %v, got %v", kind, formatNumber(e.Value))
+		}
+		if !inInt64Range(e.Value) {
+			return value{}, fmt.Errorf("seed %v is out of range", formatNumber(e.Value))
+		}
+		return value{seed: int64(e.Value)}, nil
+	}
+	return value{n: e.Value}, nil
+}
+
+// inInt64Range checks if the integer v may be converted to an int64. -2^63 is the smallest int64, while 2^63 is the
+// smallest float64 that is too big.
+func inInt64Range(v float64) bool {
+	return v >= math.MinInt64 && v < -math.MinInt64
+}
+
+// parser parses the text representation of an Expr.
+type parser struct {
+	s   string
+	pos int
+}
+
+// expr parses an Expr at the current position: A number, the seed or a function call.
+func (p *parser) expr() (Expr, error) {
+	p.skipSpace()
+	if p.pos == len(p.s) {
+		return Expr{}, fmt.Errorf("unexpected end of expression")
+	}
+	if c := p.s[p.pos]; c == '-' || c == '+' || c == '.' || isDigit(c) {
+		n, err := p.number()
+		return Expr{Value: n}, err
+	}
+	start := p.pos
+	name := p.ident()
+	if name == "" {
+		return Expr{}, fmt.Errorf("unexpected %q at position %v", p.s[p.pos], p.pos)
+	}
+	if name == "seed" {
+		return p.seed()
+	}
+	if p.skipSpace(); p.pos == len(p.s) || p.s[p.pos] != '(' {
+		return Expr{}, fmt.Errorf("expected '(' after %v at position %v", name, start)
+	}
+	p.pos++
+
+	e := Expr{Func: name}
+	if p.skipSpace(); p.pos < len(p.s) && p.s[p.pos] == ')' {
+		p.pos++
+		return e, nil
+	}
+	for {
+		arg, err := p.expr()
+		if err != nil {
+			return Expr{}, err
+		}
+		e.Args = append(e.Args, arg)
+
+		p.skipSpace()
+		if p.pos == len(p.s) {
+			return Expr{}, fmt.Errorf("missing ')' for %v at position %v", name, start)
+		}
+		switch p.s[p.pos] {
+		case ',':
+			p.pos++
+		case ')':
+			p.pos++
+			return e, nil
+		default:
+			return Expr{}, fmt.Errorf("unexpected %q at position %v", p.s[p.pos], p.pos)
+		}
+	}
+}
+
+// seed parses the optional offset following `seed`.
+func (p *parser) seed() (Expr, error) {
+	if p.skipSpace(); p.pos == len(p.s) || (p.s[p.pos] != '+' && p.s[p.pos] != '-') {
+		return Expr{Seed: true}, nil
+	}
+	sign := 1.0
+	if p.s[p.pos] == '-' {
+		sign = -1
+	}
+	p.pos++
+	p.skipSpace()
+	n, err := p.number()
+	return Expr{Seed: true, Value: sign * n}, err
+}
+
+// number parses a number at the current position. Numbers may be written in decimal, optionally with a fraction and
+// exponent, or as a hexadecimal integer prefixed with 0x.
+func (p *parser) number() (float64, error) {
+	start := p.pos
+	if p.pos < len(p.s) && (p.s[p.pos] == '-' || p.s[p.pos] == '+') {
+		p.pos++
+	}
+	if p.pos == len(p.s) {
+		return 0, fmt.Errorf("unexpected end of expression")
+	}
+	if strings.HasPrefix(p.s[p.pos:], "0x") || strings.HasPrefix(p.s[p.pos:], "0X") {
+		p.pos += 2
+		for p.pos < len(p.s) && isHexDigit(p.s[p.pos]) {
+			p.pos++
+		}
+		n, err := strconv.ParseInt(p.s[start:p.pos], 0, 64)
+		if err != nil {
+			return 0, fmt.Errorf("invalid number %q at position %v", p.s[start:p.pos], start)
+		}
+		return float64(n), nil
+	}
+	for p.pos < len(p.s) {
+		c := p.s[p.pos]
+		if isDigit(c) || c == '.' || c == 'e' || c == 'E' || ((c == '-' || c == '+') && (p.s[p.pos-1] == 'e' || p.s[p.pos-1] == 'E')) {
+			p.pos++
+			continue
+		}
+		break
+	}
+	// ParseFloat returns an error for numbers too big to be represented, so that numbers are always finite.
+	n, err := strconv.ParseFloat(p.s[start:p.pos], 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid number %q at position %v", p.s[start:p.pos], start)
+	}
+	return n, nil
+}
+
+// ident parses an identifier at the current position, consisting of letters, digits and underscores.
+func (p *parser) ident() string {
+	start := p.pos
+	for p.pos < len(p.s) {
+		c := p.s[p.pos]
+		if c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (p.pos > start && isDigit(c)) {
+			p.pos++
+			continue
+		}
+		break
+	}
+	return p.s[start:p.pos]
+}
+
+// skipSpace moves the position of the parser past any whitespace.
+func (p *parser) skipSpace() {
+	for p.pos < len(p.s) && (p.s[p.pos] == ' ' || p.s[p.pos] == '\t' || p.s[p.pos] == '\n' || p.s[p.pos] == '\r') {
+		p.pos++
+	}
+}
+
+// isDigit checks if c is a decimal digit.
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+// isHexDigit checks if c is a hexadecimal digit.
+func isHexDigit(c byte) bool {
+	return isDigit(c) || (c >= 'a' && c <= 'f') || (c >= 'A' && c <= 'F')
+}
@@ -0,0 +1,190 @@
+package f
+
+import (
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// testPositions are the positions at which functions are compared in tests.
+var testPositions = [][2]float64{{0, 0}, {1.5, -2.25}, {-37, 112}, {400.125, 33}, {-1000, -1000}}
+
+// exprTests holds an expression for every function that may be called in an Expr.
+var exprTests = []string{
+	"noise(seed, 3, 2, 0.5)",
+	"perlin(seed+1, 3, 2, 0.5)",
+	"value(seed-2, 2, 2, 0.5)",
+	"worley_f1(seed, 1, 2, 0.5)",
+	"worley_f2(seed, 2, 2, 0.5)",
+	"worley_f2f1(seed, 1, 2, 0.5)",
+	"worley_cell(seed, 1, 2, 0.5)",
+	"ridged(seed+0x10, 3, 2, 0.5)",
+	"billow(seed-0x10, 3, 2, 0.5)",
+	"fractal(12, 4, 2, 0.5)",
+	"sum(noise(seed, 1, 2, 0.5), perlin(seed, 1, 2, 0.5), 0.25)",
+	"norm(noise(seed, 3, 2, 0.5))",
+	"inv(norm(noise(seed, 3, 2, 0.5)))",
+	"pow(norm(noise(seed, 3, 2, 0.5)), 1.5)",
+	"thresh(norm(noise(seed, 3, 2, 0.5)), 0.5)",
+	"abs(noise(seed, 3, 2, 0.5))",
+	"mul(noise(seed, 3, 2, 0.5), -3)",
+	"freq(noise(seed, 3, 2, 0.5), 0.25)",
+	"mulf(noise(seed, 3, 2, 0.5), norm(perlin(seed+1, 2, 2, 0.5)))",
+	"slope(noise(seed, 3, 2, 0.5), 0.003)",
+	"warp(norm(noise(seed, 3, 2, 0.5)), 0.4, 40)",
+	"spline(norm(noise(seed, 3, 2, 0.5)), 0, 0.05, 0, 0.4, 0.1, 0.5, 1, norm(perlin(seed+1, 3, 2, 0.5)), 0)",
+	"spline(noise(seed, 3, 2, 0.5), -1, spline(perlin(seed+1, 2, 2, 0.5), 0, 0, 1, 1, 1, 1), 0, 1, 2, 0)",
+	"linear_spline(noise(seed, 3, 2, 0.5), -0.5, 0, 0.5, linear_spline(perlin(seed, 1, 2, 0.5), 0, 1, 1, 2))",
+	"add(noise(seed, 3, 2, 0.5), 1)",
+	"sub(noise(seed, 3, 2, 0.5), perlin(seed, 3, 2, 0.5))",
+	"div(noise(seed, 3, 2, 0.5), 2)",
+	"min(noise(seed, 3, 2, 0.5), perlin(seed, 3, 2, 0.5), 0.2)",
+	"max(noise(seed, 3, 2, 0.5))",
+	"clamp(noise(seed, 3, 2, 0.5), -0.25, 0.25)",
+	"lerp(noise(seed, 3, 2, 0.5), 1, norm(perlin(seed, 3, 2, 0.5)))",
+	"select(norm(perlin(seed, 2, 2, 0.5)), 0, noise(seed, 3, 2, 0.5), 0.5, 0.1)",
+	"terrace(norm(noise(seed, 3, 2, 0.5)), 6)",
+	"translate(noise(seed, 3, 2, 0.5), 100, -50.5)",
+	"rotate(noise(seed, 3, 2, 0.5), 0.7853981633974483)",
+	"scale(noise(seed, 3, 2, 0.5), 2, 0.5)",
+	"turbulence(noise(seed, 3, 2, 0.5), seed+7, 0.1, 8)",
+	"1.5e-07",
+}
+
+func TestExprRoundTrip(t *testing.T) {
+	for _, s := range exprTests {
+		e, err := Parse(s)
+		if err != nil {
+			t.Errorf("parse %q: %v", s, err)
+			continue
+		}
+		text := e.String()
+		parsed, err := Parse(text)
+		if err != nil {
+			t.Errorf("parse %q written as %q: %v", s, text, err)
+			continue
+		}
+		if !reflect.DeepEqual(parsed, e) {
+			t.Errorf("%q parsed back as %#v, expected %#v", text, parsed, e)
+		}
+		if parsed.String() != text {
+			t.Errorf("%q written as %q, then as %q", s, text, parsed.String())
+		}
+		a, err := e.Compile(5)
+		if err != nil {
+			t.Errorf("compile %q: %v", s, err)
+			continue
+		}
+		b, err := parsed.Compile(5)
+		if err != nil {
+			t.Errorf("compile %q: %v", text, err)
+			continue
+		}
+		for _, pos := range testPositions {
+			if va, vb := a(pos[0], pos[1]), b(pos[0], pos[1]); va != vb && !(math.IsNaN(va) && math.IsNaN(vb)) {
+				t.Errorf("%q at %v: %v, parsed back: %v", s, pos, va, vb)
+			}
+		}
+	}
+}
+
+func TestExprFunctionsCovered(t *testing.T) {
+	tested := map[string]bool{}
+	for _, s := range exprTests {
+		var visit func(e Expr)
+		visit = func(e Expr) {
+			tested[e.Func] = true
+			for _, arg := range e.Args {
+				visit(arg)
+			}
+		}
+		visit(MustParse(s))
+	}
+	for name := range functions {
+		if !tested[name] {
+			t.Errorf("function %v is not tested", name)
+		}
+	}
+}
+
+func TestExprCompile(t *testing.T) {
+	for s, want := range map[string]F{
+		"noise(seed, 3, 2, 0.5)":                      Noise(5, 3, 2, 0.5),
+		"noise(seed+3, 3, 2, 0.5)":                    Noise(8, 3, 2, 0.5),
+		"noise(seed-0x10, 3, 2, 0.5)":                 Noise(-11, 3, 2, 0.5),
+		"noise(100, 3, 2, 0.5)":                       Noise(100, 3, 2, 0.5),
+		"warp(norm(noise(seed, 3, 2, 0.5)), 0.4, 40)": Noise(5, 3, 2, 0.5).Norm().WarpDomain(0.4, 40),
+		"spline(noise(seed, 1, 2, 0.5), 0, 0, 1, 1, norm(perlin(seed, 1, 2, 0.5)), 0)": Noise(5, 1, 2, 0.5).Spline(
+			SplinePoint{Location: 0, Value: Const(0), Derivative: 1},
+			SplinePoint{Location: 1, Value: Perlin(5, 1, 2, 0.5).Norm()},
+		),
+		"turbulence(noise(seed, 3, 2, 0.5), seed+7, 0.1, 8)": Noise(5, 3, 2, 0.5).Turbulence(12, 0.1, 8),
+		"sum(noise(seed, 1, 2, 0.5), 0.25)":                  Sum(Noise(5, 1, 2, 0.5), Const(0.25)),
+	} {
+		got, err := MustParse(s).Compile(5)
+		if err != nil {
+			t.Errorf("compile %q: %v", s, err)
+			continue
+		}
+		for _, pos := range testPositions {
+			if a, b := got(pos[0], pos[1]), want(pos[0], pos[1]); a != b {
+				t.Errorf("%q at %v: %v, expected %v", s, pos, a, b)
+			}
+		}
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	for s, msg := range map[string]string{
+		"":                        "unexpected end of expression",
+		"noise(seed, 3":           "missing ')'",
+		"noise seed":              "expected '('",
+		"noise(seed, 3, 2, 0.5)x": "unexpected 'x'",
+		"noise(seed,, 3)":         "unexpected ','",
+		"1e400":                   "invalid number",
+		"-1e400":                  "invalid number",
+		"inf":                     "expected '('",
+		"NaN":                     "expected '('",
+		"seed+":                   "unexpected end of expression",
+		"noise(seed-":             "unexpected end of expression",
+		"-":                       "unexpected end of expression",
+	} {
+		if _, err := Parse(s); err == nil || !strings.Contains(err.Error(), msg) {
+			t.Errorf("parse %q: error %v returned, expected error containing %q", s, err, msg)
+		}
+	}
+}
+
+func TestCompileErrors(t *testing.T) {
+	for s, msg := range map[string]string{
+		"nosie(seed, 3, 2, 0.5)":                   "unknown function nosie",
+		"noise(seed, 3, 2)":                        "expected 4 arguments, got 3",
+		"noise(seed, 2.5, 2, 0.5)":                 "expected integer, got 2.5",
+		"noise(seed+0.5, 3, 2, 0.5)":               "seed offset 0.5 is not an integer",
+		"norm(seed)":                               "expected function, got seed",
+		"mul(noise(seed, 3, 2, 0.5), abs(1))":      "expected number, got abs",
+		"sum()":                                    "expected at least 1 arguments, got 0",
+		"spline(seed, 0, 1)":                       "expected 4 arguments plus a multiple of 3, got 3",
+		"linear_spline(1, 0, 1, 2)":                "expected 3 arguments plus a multiple of 2, got 4",
+		"terrace(norm(noise(seed, 3, 2, 0.5)), 0)": "terrace: steps 0 is less than 1",
+		"terrace(1, -2)":                           "terrace: steps -2 is less than 1",
+		"terrace(1, 1e300)":                        "integer 1e+300 is out of range",
+		"noise(seed, -1, 2, 0.5)":                  "noise: octaves -1 is not in the range [1 64]",
+		"noise(seed, 0, 2, 0.5)":                   "noise: octaves 0 is not in the range [1 64]",
+		"ridged(seed, -1, 2, 0.5)":                 "ridged: octaves -1 is not in the range [1 64]",
+		"fractal(seed, 100000000, 2, 0.5)":         "fractal: octaves 1e+08 is not in the range [1 64]",
+		"noise(1e19, 3, 2, 0.5)":                   "seed 1e+19 is out of range",
+		"noise(seed+1e19, 3, 2, 0.5)":              "seed offset 1e+19 is out of range",
+	} {
+		if _, err := MustParse(s).Compile(0); err == nil || !strings.Contains(err.Error(), msg) {
+			t.Errorf("compile %q: error %v returned, expected error containing %q", s, err, msg)
+		}
+	}
+	for _, v := range []float64{math.Inf(1), math.Inf(-1), math.NaN()} {
+		e := Expr{Func: "mul", Args: []Expr{{Value: 1}, {Value: v}}}
+		if _, err := e.Compile(0); err == nil || !strings.Contains(err.Error(), "is not a finite number") {
+			t.Errorf("compile %v: error %v returned, expected non-finite number to be rejected", e, err)
+		}
+	}
+}
@@ -0,0 +1,132 @@
+package f
+
+import (
+	"github.com/ojrac/opensimplex-go"
+	"math"
+)
+
+// Fractal describes layered (fractal) simplex noise. Unlike Noise, which seeds every octave with the same seed,
+// Fractal derives an independent seed for every octave from Seed, so that the octaves are not correlated. Zero values
+// are replaced with their defaults when the F is built, so that Fractal{Seed: s, Octaves: o, Lacunarity: l,
+// Persistence: p}.F() is a drop-in replacement for Noise(s, o, l, p), with the same frequency and range.
+type Fractal struct {
+	// Seed is the seed that the seeds of all octaves are derived from.
+	Seed int64
+	// Octaves is the amount of noise layers that are added together. Defaults to 1.
+	Octaves int
+	// Lacunarity and Persistence specify the frequency and amplitude of every octave relative to the octave before
+	// it, like the parameters of Noise. They default to 2 and 0.5 respectively.
+	Lacunarity, Persistence float64
+	// Frequency is the frequency of the first octave. Defaults to 0.04, the frequency of the first octave of Noise.
+	Frequency float64
+	// Amplitude is the amplitude of the noise: The F returned produces values in the range [-Amplitude, Amplitude].
+	// Defaults to 1.
+	Amplitude float64
+	// Rotate specifies if every octave should be rotated by a different angle and offset by a random distance. This
+	// breaks up the artifacts along the axes that are visible when many octaves are layered on top of each other.
+	Rotate bool
+}
+
+// goldenAngle is the angle in radians that every octave of a Fractal is rotated by relative to the octave before it.
+// Rotating by the golden angle ensures that no two octaves have a similar rotation.
+const goldenAngle = 2.399963229728653
+
+// octave is a single layer of noise of a Fractal.
+type octave struct {
+	seed       int64
+	freq, amp  float64
+	sin, cos   float64
+	offX, offY float64
+}
+
+// F builds the F that calculates the noise described by the Fractal. Its values are guaranteed to be in the range
+// [-Amplitude, Amplitude].
+func (fr Fractal) F() F {
+	fr = fr.withDefaults()
+	octaves, max := fr.octaves()
+	noise := make([]opensimplex.Noise, len(octaves))
+	for i, o := range octaves {
+		noise[i] = opensimplex.New(o.seed)
+	}
+
+	return func(x, y float64) float64 {
+		var v float64
+		for i, o := range octaves {
+			ox, oy := x*o.freq, y*o.freq
+			v += o.amp * noise[i].Eval2(ox*o.cos-oy*o.sin+o.offX, ox*o.sin+oy*o.cos+o.offY)
+		}
+		// Simplex noise may slightly exceed its nominal range, so the value is clamped after normalising it.
+		v = math.Max(-1, math.Min(1, v/max))
+		return v * fr.Amplitude
+	}
+}
+
+// FD builds an FD that calculates the same noise as the F returned by F, along with its analytic derivatives. Where
+// the value is clamped to [-Amplitude, Amplitude], the derivatives are 0.
+func (fr Fractal) FD() FD {
+	fr = fr.withDefaults()
+	octaves, max := fr.octaves()
+	noise := make([]*simplexD, len(octaves))
+	for i, o := range octaves {
+		noise[i] = newSimplexD(o.seed)
+	}
+
+	return func(x, y float64) (float64, float64, float64) {
+		var v, dx, dy float64
+		for i, o := range octaves {
+			ox, oy := x*o.freq, y*o.freq
+			nv, ndx, ndy := noise[i].eval(ox*o.cos-oy*o.sin+o.offX, ox*o.sin+oy*o.cos+o.offY)
+			// The derivatives are rotated back by the rotation of the octave and scaled by its frequency.
+			v += o.amp * nv
+			dx += o.amp * o.freq * (ndx*o.cos + ndy*o.sin)
+			dy += o.amp * o.freq * (ndy*o.cos - ndx*o.sin)
+		}
+		v /= max
+		if v < -1 || v > 1 {
+			return math.Max(-1, math.Min(1, v)) * fr.Amplitude, 0, 0
+		}
+		return v * fr.Amplitude, dx / max * fr.Amplitude, dy / max * fr.Amplitude
+	}
+}
+
+// octaves returns the octaves of the Fractal, which must already have its defaults, along with the sum of their
+// amplitudes, by which the noise is normalised.
+func (fr Fractal) octaves() ([]octave, float64) {
+	octaves := make([]octave, fr.Octaves)
+	var max float64
+	for i := range octaves {
+		o := octave{
+			seed: int64(hash2(fr.Seed, int64(i), 0)),
+			freq: fr.Frequency * math.Pow(fr.Lacunarity, float64(i)),
+			amp:  math.Pow(fr.Persistence, float64(i)),
+			cos:  1,
+		}
+		if fr.Rotate {
+			o.sin, o.cos = math.Sincos(float64(i) * goldenAngle)
+			o.offX, o.offY = unit(hash2(fr.Seed, int64(i), 1))*1000, unit(hash2(fr.Seed, int64(i), 2))*1000
+		}
+		octaves[i] = o
+		max += o.amp
+	}
+	return octaves, max
+}
+
+// withDefaults returns a copy of the Fractal with all zero values replaced with their default values.
+func (fr Fractal) withDefaults() Fractal {
+	if fr.Octaves <= 0 {
+		fr.Octaves = 1
+	}
+	if fr.Lacunarity == 0 {
+		fr.Lacunarity = 2
+	}
+	if fr.Persistence == 0 {
+		fr.Persistence = 0.5
+	}
+	if fr.Frequency == 0 {
+		fr.Frequency = 0.04
+	}
+	if fr.Amplitude == 0 {
+		fr.Amplitude = 1
+	}
+	return fr
+}
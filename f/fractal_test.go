@@ -0,0 +1,76 @@
+package f
+
+import (
+	"math"
+	"testing"
+)
+
+func TestFractalRange(t *testing.T) {
+	for _, test := range []struct {
+		name string
+		fr   Fractal
+	}{
+		{"Default", Fractal{Seed: 1}},
+		{"Octaves", Fractal{Seed: 1, Octaves: 6}},
+		{"Amplitude", Fractal{Seed: 2, Octaves: 4, Amplitude: 30}},
+		{"NegativeAmplitude", Fractal{Seed: 2, Octaves: 4, Amplitude: -3}},
+		{"Persistence", Fractal{Seed: 3, Octaves: 5, Lacunarity: 3, Persistence: 0.8}},
+		// With a persistence above 1, higher octaves dominate, but the value must still be normalised.
+		{"HighPersistence", Fractal{Seed: 3, Octaves: 5, Persistence: 1.5}},
+		{"Frequency", Fractal{Seed: 4, Octaves: 3, Frequency: 0.3}},
+		{"Rotate", Fractal{Seed: 5, Octaves: 8, Rotate: true}},
+		{"ManyOctaves", Fractal{Seed: 6, Octaves: 32, Persistence: 0.6, Rotate: true}},
+	} {
+		amp := test.fr.Amplitude
+		if amp == 0 {
+			amp = 1
+		}
+		min, max := -math.Abs(amp), math.Abs(amp)
+		fn := test.fr.F()
+		// The noise should not only stay within its range, but also cover a good part of it.
+		low, high := math.Inf(1), math.Inf(-1)
+		testGrid(func(x, y float64) {
+			v := fn(x, y)
+			if !inRange(v, min, max) {
+				t.Fatalf("%v at %v, %v: %v outside of range [%v %v]", test.name, x, y, v, min, max)
+			}
+			low, high = math.Min(low, v), math.Max(high, v)
+		})
+		if low > min*0.3 || high < max*0.3 {
+			t.Errorf("%v: values in range [%v %v], expected them to spread out over [%v %v]", test.name, low, high, min, max)
+		}
+	}
+}
+
+func TestFractalDefaults(t *testing.T) {
+	a := Fractal{Seed: 7}.F()
+	b := Fractal{Seed: 7, Octaves: 1, Lacunarity: 2, Persistence: 0.5, Frequency: 0.04, Amplitude: 1}.F()
+	scaled := Fractal{Seed: 7, Amplitude: 5}.F()
+	testGrid(func(x, y float64) {
+		if va, vb := a(x, y), b(x, y); va != vb {
+			t.Fatalf("Fractal with defaults at %v, %v: %v, expected %v", x, y, va, vb)
+		}
+		if v, want := scaled(x, y), a(x, y)*5; math.Abs(v-want) > 1e-12 {
+			t.Fatalf("Fractal with amplitude 5 at %v, %v: %v, expected %v", x, y, v, want)
+		}
+	})
+}
+
+func TestFractalSeed(t *testing.T) {
+	for _, rotate := range []bool{false, true} {
+		a, b := Fractal{Seed: 1, Octaves: 4, Rotate: rotate}.F(), Fractal{Seed: 1, Octaves: 4, Rotate: rotate}.F()
+		other := Fractal{Seed: 2, Octaves: 4, Rotate: rotate}.F()
+		var differs bool
+		testGrid(func(x, y float64) {
+			if va, vb := a(x, y), b(x, y); va != vb {
+				t.Fatalf("Fractal with rotate %v at %v, %v: %v and %v for the same seed", rotate, x, y, va, vb)
+			}
+			if a(x, y) != other(x, y) {
+				differs = true
+			}
+		})
+		if !differs {
+			t.Errorf("Fractal with rotate %v: same values for seeds 1 and 2", rotate)
+		}
+	}
+}
@@ -0,0 +1,318 @@
+package f
+
+import (
+	"math"
+)
+
+// FD is a function like F that returns, along with its value, the partial derivatives of the value with respect to
+// x and y. The derivatives are computed analytically and propagated through the methods of FD using the chain rule,
+// which is both cheaper and more accurate than estimating them with F.Slope.
+// Only NoiseD, PerlinD, Fractal.FD and the methods below have analytic derivatives: FD has no counterparts for the
+// other noise functions, F.Thresh, F.Spline, F.LinearSpline or the combinators of op.go such as Min, Max, Select and
+// Terrace. Functions using those must be built as an F, of which the slope can only be estimated using F.Slope.
+type FD func(x, y float64) (v, dx, dy float64)
+
+// NoiseD returns an FD that calculates the same layered noise as Noise with the same parameters, along with its
+// analytic derivatives.
+func NoiseD(seed int64, octaves int, lacunarity, persistence float64) FD {
+	n := newSimplexD(seed)
+
+	var max float64
+	for i := 0; i < octaves; i++ {
+		max += math.Pow(persistence, float64(i))
+	}
+
+	return func(x, y float64) (float64, float64, float64) {
+		var v, dx, dy float64
+
+		for i := 0; i < octaves; i++ {
+			freq := math.Pow(lacunarity, float64(i)) * 0.04
+			amp := math.Pow(persistence, float64(i))
+
+			nv, ndx, ndy := n.eval(x*freq, y*freq)
+			v += amp * nv
+			dx += amp * freq * ndx
+			dy += amp * freq * ndy
+		}
+		return v / max, dx / max, dy / max
+	}
+}
+
+// PerlinD returns an FD that calculates the same layered gradient noise as Perlin with the same parameters, along
+// with its analytic derivatives.
+func PerlinD(seed int64, octaves int, lacunarity, persistence float64) FD {
+	var max float64
+	for i := 0; i < octaves; i++ {
+		max += math.Pow(persistence, float64(i))
+	}
+
+	return func(x, y float64) (float64, float64, float64) {
+		var v, dx, dy float64
+
+		for i := 0; i < octaves; i++ {
+			freq := math.Pow(lacunarity, float64(i)) * 0.04
+			amp := math.Pow(persistence, float64(i))
+
+			nv, ndx, ndy := perlinD(seed, x*freq, y*freq)
+			v += amp * nv
+			dx += amp * freq * ndx
+			dy += amp * freq * ndy
+		}
+		return v / max, dx / max, dy / max
+	}
+}
+
+// perlinD evaluates a single octave of the gradient noise of Perlin at x, y and returns its value and its partial
+// derivatives.
+func perlinD(seed int64, x, y float64) (v, dx, dy float64) {
+	x0, y0 := math.Floor(x), math.Floor(y)
+	fx, fy := x-x0, y-y0
+	ix, iy := int64(x0), int64(y0)
+
+	g00, g10 := gradients[hash2(seed, ix, iy)&7], gradients[hash2(seed, ix+1, iy)&7]
+	g01, g11 := gradients[hash2(seed, ix, iy+1)&7], gradients[hash2(seed, ix+1, iy+1)&7]
+	n00, n10 := gradient(seed, ix, iy, fx, fy), gradient(seed, ix+1, iy, fx-1, fy)
+	n01, n11 := gradient(seed, ix, iy+1, fx, fy-1), gradient(seed, ix+1, iy+1, fx-1, fy-1)
+
+	// The derivative of the quintic fade curve is 30t^2(t-1)^2.
+	u, w := fade(fx), fade(fy)
+	du, dw := 30*fx*fx*(fx-1)*(fx-1), 30*fy*fy*(fy-1)*(fy-1)
+
+	a, b := lerp(n00, n10, u), lerp(n01, n11, u)
+	adx, ady := lerp(g00[0], g10[0], u)+(n10-n00)*du, lerp(g00[1], g10[1], u)
+	bdx, bdy := lerp(g01[0], g11[0], u)+(n11-n01)*du, lerp(g01[1], g11[1], u)
+	return lerp(a, b, w), lerp(adx, bdx, w), lerp(ady, bdy, w) + (b-a)*dw
+}
+
+// SumD sums up multiple functions and returns a new FD that is the result of adding up the functions.
+func SumD(f ...FD) FD {
+	if len(f) == 1 {
+		return f[0]
+	}
+	return func(x, y float64) (float64, float64, float64) {
+		var v, dx, dy float64
+		for _, fu := range f {
+			fv, fdx, fdy := fu(x, y)
+			v, dx, dy = v+fv, dx+fdx, dy+fdy
+		}
+		return v, dx, dy
+	}
+}
+
+// F returns an F that returns only the values of the FD.
+func (f FD) F() F {
+	return func(x, y float64) float64 {
+		v, _, _ := f(x, y)
+		return v
+	}
+}
+
+// Slope returns an F that returns the slope of the FD at a position: The magnitude of its gradient.
+func (f FD) Slope() F {
+	return func(x, y float64) float64 {
+		_, dx, dy := f(x, y)
+		return math.Sqrt(dx*dx + dy*dy)
+	}
+}
+
+// SlopeD returns an FD that returns the slope of the old function at a position, like FD.Slope. The value is exact,
+// but as it depends on the derivatives of the old function, the derivatives of the slope are approximated from the
+// analytic gradients at a distance dx/2 on either side of the position. This makes the FD returned five times as
+// expensive as FD.Slope, so SlopeD should only be used where the derivatives of the slope are needed.
+func (f FD) SlopeD(dx float64) FD {
+	slope := f.Slope()
+	return func(x, y float64) (float64, float64, float64) {
+		return slope(x, y), (slope(x+dx/2, y) - slope(x-dx/2, y)) / dx, (slope(x, y+dx/2) - slope(x, y-dx/2)) / dx
+	}
+}
+
+// Norm normalises the results of the old function and returns a new FD that returns values in the range [0 1) as
+// opposed to (-1 1).
+func (f FD) Norm() FD {
+	return func(x, y float64) (float64, float64, float64) {
+		v, dx, dy := f(x, y)
+		return (v + 1) / 2, dx / 2, dy / 2
+	}
+}
+
+// Inv inverses the results of the old function and returns a new FD that has its values [0 1) changed to [1 0).
+func (f FD) Inv() FD {
+	return func(x, y float64) (float64, float64, float64) {
+		v, dx, dy := f(x, y)
+		return 1 - v, -dx, -dy
+	}
+}
+
+// Pow returns a new FD that raises values returned by the old function to the power of v.
+func (f FD) Pow(v float64) FD {
+	return func(x, y float64) (float64, float64, float64) {
+		fv, dx, dy := f(x, y)
+		d := v * math.Pow(fv, v-1)
+		return math.Pow(fv, v), d * dx, d * dy
+	}
+}
+
+// Abs returns a new FD that only returns absolute values. The derivatives at values of 0 are those of the old
+// function.
+func (f FD) Abs() FD {
+	return func(x, y float64) (float64, float64, float64) {
+		v, dx, dy := f(x, y)
+		if v < 0 {
+			return -v, -dx, -dy
+		}
+		return v, dx, dy
+	}
+}
+
+// Mul returns a new FD that returns values by the old function multiplied by a value v.
+func (f FD) Mul(v float64) FD {
+	return func(x, y float64) (float64, float64, float64) {
+		fv, dx, dy := f(x, y)
+		return fv * v, dx * v, dy * v
+	}
+}
+
+// MulF returns a new FD that returns values of the old function multiplied by the value of the function v at the
+// same x and y values.
+func (f FD) MulF(v FD) FD {
+	return func(x, y float64) (float64, float64, float64) {
+		a, adx, ady := f(x, y)
+		b, bdx, bdy := v(x, y)
+		return a * b, adx*b + a*bdx, ady*b + a*bdy
+	}
+}
+
+// Freq returns a new FD with frequency v.
+func (f FD) Freq(v float64) FD {
+	return func(x, y float64) (float64, float64, float64) {
+		fv, dx, dy := f(x*v, y*v)
+		return fv, dx * v, dy * v
+	}
+}
+
+// WarpDomain returns a new FD that performs domain warping on the old function, exactly like F.WarpDomain.
+func (f FD) WarpDomain(freq, warp float64) FD {
+	i, j, k, l := 0.0, 0.0, 5.3, 1.3
+	return func(x, y float64) (float64, float64, float64) {
+		warpX, wxdx, wxdy := f(x*freq+i, y*freq+j)
+		warpY, wydx, wydy := f(x*freq+k, y*freq*l)
+		v, dx, dy := f(x*freq+warpX*warp, y*freq+warpY*warp)
+
+		// Partial derivatives of the warped coordinates u and w with respect to x and y.
+		udx, udy := freq+warp*wxdx*freq, warp*wxdy*freq
+		wdx, wdy := warp*wydx*freq, freq+warp*wydy*freq*l
+		return v, dx*udx + dy*wdx, dx*udy + dy*wdy
+	}
+}
+
+// simplexD calculates OpenSimplex noise along with its analytic derivatives. It produces exactly the same values as
+// the opensimplex.Noise used by Noise for the same seed.
+type simplexD struct {
+	perm [256]int16
+}
+
+const (
+	// stretch2D and squish2D are the constants used to skew the input coordinates onto the grid of OpenSimplex noise
+	// and back.
+	stretch2D = -0.211324865405187
+	squish2D  = 0.366025403784439
+	// norm2D is the constant that the sum of all contributions is divided by.
+	norm2D = 47
+)
+
+// gradients2D holds the gradients of 2D OpenSimplex noise, which approximate the directions to the vertices of an
+// octagon from its centre.
+var gradients2D = [16]float64{
+	5, 2, 2, 5,
+	-5, 2, -2, 5,
+	5, -2, 2, -5,
+	-5, -2, -2, -5,
+}
+
+// newSimplexD creates a simplexD with the permutation table that opensimplex.New creates for the seed passed.
+func newSimplexD(seed int64) *simplexD {
+	s := &simplexD{}
+	var source [256]int16
+	for i := range source {
+		source[i] = int16(i)
+	}
+	for i := 0; i < 3; i++ {
+		seed = seed*6364136223846793005 + 1442695040888963407
+	}
+	for i := int32(255); i >= 0; i-- {
+		seed = seed*6364136223846793005 + 1442695040888963407
+		r := int32((seed + 31) % int64(i+1))
+		if r < 0 {
+			r += i + 1
+		}
+		s.perm[i] = source[r]
+		source[r] = source[i]
+	}
+	return s
+}
+
+// eval evaluates the noise at x, y and returns the value in the range (-1 1) and its partial derivatives.
+func (s *simplexD) eval(x, y float64) (v, dx, dy float64) {
+	// Skew the input coordinates onto the grid and find the origin of the rhombus super-cell they are in.
+	stretch := (x + y) * stretch2D
+	xs, ys := x+stretch, y+stretch
+	xsb, ysb := int32(math.Floor(xs)), int32(math.Floor(ys))
+
+	squish := float64(xsb+ysb) * squish2D
+	xins, yins := xs-float64(xsb), ys-float64(ysb)
+	inSum := xins + yins
+	dx0, dy0 := x-(float64(xsb)+squish), y-(float64(ysb)+squish)
+
+	// Every offset below is x or y minus a constant, so the derivative of the offsets with respect to x and y is 1.
+	s.contribute(xsb+1, ysb, dx0-1-squish2D, dy0-squish2D, &v, &dx, &dy)
+	s.contribute(xsb, ysb+1, dx0-squish2D, dy0-1-squish2D, &v, &dx, &dy)
+
+	var dxExt, dyExt float64
+	var xsvExt, ysvExt int32
+	if inSum <= 1 {
+		if zins := 1 - inSum; zins > xins || zins > yins {
+			if xins > yins {
+				xsvExt, ysvExt, dxExt, dyExt = xsb+1, ysb-1, dx0-1, dy0+1
+			} else {
+				xsvExt, ysvExt, dxExt, dyExt = xsb-1, ysb+1, dx0+1, dy0-1
+			}
+		} else {
+			xsvExt, ysvExt, dxExt, dyExt = xsb+1, ysb+1, dx0-1-2*squish2D, dy0-1-2*squish2D
+		}
+	} else {
+		if zins := 2 - inSum; zins < xins || zins < yins {
+			if xins > yins {
+				xsvExt, ysvExt, dxExt, dyExt = xsb+2, ysb, dx0-2-2*squish2D, dy0-2*squish2D
+			} else {
+				xsvExt, ysvExt, dxExt, dyExt = xsb, ysb+2, dx0-2*squish2D, dy0-2-2*squish2D
+			}
+		} else {
+			xsvExt, ysvExt, dxExt, dyExt = xsb, ysb, dx0, dy0
+		}
+		xsb, ysb = xsb+1, ysb+1
+		dx0, dy0 = dx0-1-2*squish2D, dy0-1-2*squish2D
+	}
+	s.contribute(xsb, ysb, dx0, dy0, &v, &dx, &dy)
+	s.contribute(xsvExt, ysvExt, dxExt, dyExt, &v, &dx, &dy)
+
+	return v / norm2D, dx / norm2D, dy / norm2D
+}
+
+// contribute adds the contribution of the grid vertex xsb, ysb at an offset of ox, oy from the position evaluated to
+// the value v and the derivatives dx and dy. The contribution is a^4 * e, where a = 2 - ox^2 - oy^2 is the attenuation
+// and e = gx*ox + gy*oy is the gradient of the vertex extrapolated to the position.
+func (s *simplexD) contribute(xsb, ysb int32, ox, oy float64, v, dx, dy *float64) {
+	a := 2 - ox*ox - oy*oy
+	if a <= 0 {
+		return
+	}
+	i := s.perm[(int32(s.perm[xsb&0xff])+ysb)&0xff] & 0x0e
+	gx, gy := gradients2D[i], gradients2D[i+1]
+	e := gx*ox + gy*oy
+
+	a2 := a * a
+	a3 := a2 * a
+	*v += a2 * a2 * e
+	*dx += a2*a2*gx - 8*a3*ox*e
+	*dy += a2*a2*gy - 8*a3*oy*e
+}
@@ -0,0 +1,102 @@
+package f
+
+import (
+	"math"
+	"testing"
+)
+
+func TestNoiseDValues(t *testing.T) {
+	for _, seed := range []int64{0, 1, -1, 12345, math.MaxInt64, math.MinInt64} {
+		for _, params := range []struct {
+			octaves                 int
+			lacunarity, persistence float64
+		}{{1, 2, 0.5}, {3, 2, 0.5}, {3, 3, 0.6}, {5, 2.2, 0.45}} {
+			n, d := Noise(seed, params.octaves, params.lacunarity, params.persistence), NoiseD(seed, params.octaves, params.lacunarity, params.persistence).F()
+			testGrid(func(x, y float64) {
+				if a, b := n(x, y), d(x, y); a != b {
+					t.Fatalf("NoiseD(%v, %+v) at %v, %v: %v, Noise returns %v", seed, params, x, y, b, a)
+				}
+			})
+		}
+	}
+}
+
+func TestFDValues(t *testing.T) {
+	for _, test := range []struct {
+		name string
+		f    F
+		d    FD
+	}{
+		{"PerlinD", Perlin(1, 1, 2, 0.5), PerlinD(1, 1, 2, 0.5)},
+		{"PerlinDOctaves", Perlin(-7, 4, 2.2, 0.45), PerlinD(-7, 4, 2.2, 0.45)},
+		{"Fractal.FD", Fractal{Seed: 1}.F(), Fractal{Seed: 1}.FD()},
+		{"Fractal.FDOctaves", Fractal{Seed: 2, Octaves: 5, Amplitude: 3}.F(), Fractal{Seed: 2, Octaves: 5, Amplitude: 3}.FD()},
+		{"Fractal.FDRotate", Fractal{Seed: 3, Octaves: 6, Rotate: true}.F(), Fractal{Seed: 3, Octaves: 6, Rotate: true}.FD()},
+	} {
+		d := test.d.F()
+		testGrid(func(x, y float64) {
+			if a, b := test.f(x, y), d(x, y); a != b {
+				t.Fatalf("%v at %v, %v: %v, F returns %v", test.name, x, y, b, a)
+			}
+		})
+	}
+}
+
+// centralDifference estimates the partial derivatives of the value returned by the function passed at x, y.
+func centralDifference(fn func(x, y float64) float64, x, y float64) (dx, dy float64) {
+	const h = 1e-5
+	return (fn(x+h, y) - fn(x-h, y)) / (2 * h), (fn(x, y+h) - fn(x, y-h)) / (2 * h)
+}
+
+func TestFDDerivatives(t *testing.T) {
+	n, m := NoiseD(1, 3, 2, 0.5), NoiseD(2, 3, 3, 0.6)
+	for _, test := range []struct {
+		name string
+		f    FD
+		tol  float64
+	}{
+		{"NoiseD", n, 1e-7},
+		{"PerlinD", PerlinD(1, 3, 2, 0.5), 1e-7},
+		{"Fractal.FD", Fractal{Seed: 1, Octaves: 4, Amplitude: 2}.FD(), 1e-7},
+		{"Fractal.FDRotate", Fractal{Seed: 2, Octaves: 6, Frequency: 0.1, Rotate: true}.FD(), 1e-6},
+		{"SumD", SumD(n, m, m), 1e-7},
+		{"Norm", n.Norm(), 1e-7},
+		{"Inv", n.Norm().Inv(), 1e-7},
+		{"Pow", n.Norm().Pow(1.7), 1e-7},
+		{"Abs", n.Abs(), 1e-7},
+		{"Mul", n.Mul(-3), 1e-7},
+		{"MulF", n.MulF(m.Norm()), 1e-7},
+		{"Freq", n.Freq(0.3), 1e-7},
+		{"WarpDomain", n.Norm().WarpDomain(0.2, 70), 1e-6},
+		// The derivatives of SlopeD are themselves estimated, so they are less accurate.
+		{"SlopeD", n.Norm().SlopeD(0.003), 1e-4},
+		{"Mountains", SumD(n.Norm().WarpDomain(0.2, 70), m.Norm().MulF(n.Norm().WarpDomain(0.2, 70).SlopeD(0.003).Mul(10))), 1e-5},
+	} {
+		value := test.f.F()
+		testGrid(func(x, y float64) {
+			v, dx, dy := test.f(x, y)
+			if test.name == "Abs" && math.Abs(v) < 1e-3 {
+				// The absolute value has no derivative where the old function crosses 0.
+				return
+			}
+			wantX, wantY := centralDifference(value, x, y)
+			if math.Abs(dx-wantX) > test.tol || math.Abs(dy-wantY) > test.tol {
+				t.Fatalf("%v at %v, %v: derivatives %v, %v, central differences %v, %v", test.name, x, y, dx, dy, wantX, wantY)
+			}
+		})
+	}
+}
+
+func TestFDSlope(t *testing.T) {
+	n := NoiseD(1, 3, 2, 0.5).Norm().WarpDomain(0.2, 70)
+	slope, slopeD, estimate := n.Slope(), n.SlopeD(0.003).F(), n.F().Slope(0.003)
+	testGrid(func(x, y float64) {
+		s := slope(x, y)
+		if sd := slopeD(x, y); s != sd {
+			t.Fatalf("slope at %v, %v: Slope returns %v, SlopeD returns %v", x, y, s, sd)
+		}
+		if e := estimate(x, y); math.Abs(s-e) > 1e-5 {
+			t.Fatalf("slope at %v, %v: %v, F.Slope estimates %v", x, y, s, e)
+		}
+	})
+}
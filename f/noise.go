@@ -40,3 +40,65 @@ func Noise(seed int64, octaves int, lacunarity, persistence float64) F {
 		return v
 	}
 }
+
+// Noise3 returns a function that may be used to calculate a layered noise value at a specific x, y and z. It is the
+// three-dimensional counterpart of Noise and takes the same parameters. Unlike Noise, every octave is seeded with a
+// seed derived from the seed passed, like Fractal, so that the octaves are not correlated.
+func Noise3(seed int64, octaves int, lacunarity, persistence float64) F3 {
+	n := make([]opensimplex.Noise, octaves)
+	for i := 0; i < octaves; i++ {
+		n[i] = opensimplex.New(int64(hash2(seed, int64(i), 0)))
+	}
+
+	var max float64
+	for i := 0; i < octaves; i++ {
+		max += math.Pow(persistence, float64(i))
+	}
+
+	return func(x, y, z float64) float64 {
+		var v float64
+
+		for i, noise := range n {
+			freq := math.Pow(lacunarity, float64(i)) * 0.04
+			amp := math.Pow(persistence, float64(i))
+
+			v += amp * noise.Eval3(x*freq, y*freq, z*freq)
+		}
+		// Normalise at the end so we get a value in the range (-1 1).
+		v /= max
+		return v
+	}
+}
+
+// layered layers octaves of the noise function eval, which returns values in the range (-1 1), in the same way as
+// Noise: Octave i is sampled at a frequency of lacunarity^i * 0.04 and weighted with an amplitude of persistence^i. The
+// F returned is normalised to return values in the range (-1 1).
+func layered(octaves int, lacunarity, persistence float64, eval func(x, y float64) float64) F {
+	var max float64
+	for i := 0; i < octaves; i++ {
+		max += math.Pow(persistence, float64(i))
+	}
+	return func(x, y float64) float64 {
+		var v float64
+		for i := 0; i < octaves; i++ {
+			freq := math.Pow(lacunarity, float64(i)) * 0.04
+			amp := math.Pow(persistence, float64(i))
+
+			v += amp * eval(x*freq, y*freq)
+		}
+		return v / max
+	}
+}
+
+// hash2 returns a pseudo-random 64-bit hash of the integer coordinates x and y and the seed passed.
+func hash2(seed int64, x, y int64) uint64 {
+	h := uint64(seed) ^ uint64(x)*0x9e3779b97f4a7c15 ^ uint64(y)*0xc2b2ae3d27d4eb4f
+	h = (h ^ h>>30) * 0xbf58476d1ce4e5b9
+	h = (h ^ h>>27) * 0x94d049bb133111eb
+	return h ^ h>>31
+}
+
+// unit converts a hash to a float64 in the range [-1 1).
+func unit(h uint64) float64 {
+	return float64(h>>11)/(1<<52) - 1
+}
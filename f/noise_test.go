@@ -0,0 +1,123 @@
+package f
+
+import (
+	"math"
+	"testing"
+)
+
+func TestNoiseRange(t *testing.T) {
+	for _, test := range []struct {
+		name     string
+		f        F
+		min, max float64
+	}{
+		{"Noise", Noise(1, 3, 2, 0.5), -1, 1},
+		{"Perlin", Perlin(1, 1, 2, 0.5), -1, 1},
+		{"PerlinOctaves", Perlin(2, 4, 2, 0.5), -1, 1},
+		{"Value", Value(1, 1, 2, 0.5), -1, 1},
+		{"ValueOctaves", Value(2, 4, 2.5, 0.6), -1, 1},
+		{"WorleyF1", Worley(1, 1, 2, 0.5, WorleyF1), -1, 1},
+		{"WorleyF2", Worley(1, 1, 2, 0.5, WorleyF2), -1, 1},
+		{"WorleyF2F1", Worley(1, 1, 2, 0.5, WorleyF2F1), -1, 1},
+		{"WorleyCell", Worley(1, 1, 2, 0.5, WorleyCell), -1, 1},
+		{"WorleyOctaves", Worley(2, 3, 2, 0.5, WorleyF1), -1, 1},
+		{"Ridged", Ridged(1, 4, 2, 0.5), -1, 1},
+		{"Billow", Billow(1, 4, 2, 0.5), -1, 1},
+	} {
+		// The noise should not only stay within its range, but also cover a good part of it.
+		low, high := math.Inf(1), math.Inf(-1)
+		testGrid(func(x, y float64) {
+			v := test.f(x, y)
+			if !inRange(v, test.min, test.max) {
+				t.Fatalf("%v at %v, %v: %v outside of range [%v %v]", test.name, x, y, v, test.min, test.max)
+			}
+			low, high = math.Min(low, v), math.Max(high, v)
+		})
+		if low > -0.3 || high < 0.3 {
+			t.Errorf("%v: values in range [%v %v], expected them to spread out over [%v %v]", test.name, low, high, test.min, test.max)
+		}
+	}
+}
+
+func TestNoiseSeed(t *testing.T) {
+	for _, test := range []struct {
+		name string
+		new  func(seed int64) F
+	}{
+		{"Noise", func(seed int64) F { return Noise(seed, 3, 2, 0.5) }},
+		{"Perlin", func(seed int64) F { return Perlin(seed, 3, 2, 0.5) }},
+		{"Value", func(seed int64) F { return Value(seed, 3, 2, 0.5) }},
+		{"WorleyF1", func(seed int64) F { return Worley(seed, 1, 2, 0.5, WorleyF1) }},
+		{"WorleyCell", func(seed int64) F { return Worley(seed, 1, 2, 0.5, WorleyCell) }},
+		{"Ridged", func(seed int64) F { return Ridged(seed, 3, 2, 0.5) }},
+		{"Billow", func(seed int64) F { return Billow(seed, 3, 2, 0.5) }},
+	} {
+		a, b, other := test.new(1), test.new(1), test.new(2)
+		var differs bool
+		testGrid(func(x, y float64) {
+			if va, vb := a(x, y), b(x, y); va != vb {
+				t.Fatalf("%v at %v, %v: %v and %v for the same seed", test.name, x, y, va, vb)
+			}
+			if a(x, y) != other(x, y) {
+				differs = true
+			}
+		})
+		if !differs {
+			t.Errorf("%v: same values for seeds 1 and 2", test.name)
+		}
+	}
+}
+
+func TestPerlinGrid(t *testing.T) {
+	// The first octave has a frequency of 0.04, so the points of its grid are 25 blocks apart. Perlin noise is 0 on
+	// every point of the grid, while value noise takes the value assigned to the point.
+	p, v := Perlin(1, 1, 2, 0.5), Value(1, 1, 2, 0.5)
+	for x := int64(-20); x <= 20; x++ {
+		for y := int64(-20); y <= 20; y += 3 {
+			if pv := p(float64(x)*25, float64(y)*25); math.Abs(pv) > 1e-12 {
+				t.Fatalf("Perlin at grid point %v, %v: %v, expected 0", x, y, pv)
+			}
+			if vv, want := v(float64(x)*25, float64(y)*25), unit(hash2(1, x, y)); math.Abs(vv-want) > 1e-12 {
+				t.Fatalf("Value at grid point %v, %v: %v, expected %v", x, y, vv, want)
+			}
+		}
+	}
+}
+
+func TestWorleyModes(t *testing.T) {
+	f1, f2 := Worley(1, 1, 2, 0.5, WorleyF1), Worley(1, 1, 2, 0.5, WorleyF2)
+	f2f1, cell := Worley(1, 1, 2, 0.5, WorleyF2F1), Worley(1, 1, 2, 0.5, WorleyCell)
+	cells := map[float64]bool{}
+	testGrid(func(x, y float64) {
+		v1, v2, v21 := f1(x, y), f2(x, y), f2f1(x, y)
+		if v1 > v2 {
+			t.Fatalf("Worley at %v, %v: F1 %v is bigger than F2 %v", x, y, v1, v2)
+		}
+		// Distances are mapped from [0 1] to [-1 1], so F2-F1 is the difference of F2 and F1 shifted down by 1,
+		// unless F2 was clamped.
+		if v2 < 1 && math.Abs(v21-(v2-v1-1)) > 1e-12 {
+			t.Fatalf("Worley at %v, %v: F2-F1 %v, expected %v", x, y, v21, v2-v1-1)
+		}
+		cells[cell(x, y)] = true
+	})
+	// WorleyCell returns one value per cell. The cells are 25 blocks wide, so the grid of 1000x1000 blocks covers at
+	// most 41x41 cells, but it is sampled at far more positions.
+	if len(cells) < 100 || len(cells) > 41*41 {
+		t.Errorf("WorleyCell returned %v different values, expected one per cell", len(cells))
+	}
+}
+
+func TestRidged(t *testing.T) {
+	// A single octave of ridged noise is 1 minus the absolute value of simplex noise, squared and mapped to [-1 1].
+	r, n := Ridged(1, 1, 2, 0.5), Noise(1, 1, 2, 0.5)
+	b := Billow(1, 1, 2, 0.5)
+	testGrid(func(x, y float64) {
+		s := 1 - math.Abs(n(x, y))
+		if v, want := r(x, y), s*s*2-1; math.Abs(v-want) > 1e-12 {
+			t.Fatalf("Ridged at %v, %v: %v, expected %v", x, y, v, want)
+		}
+		if v, want := b(x, y), math.Abs(n(x, y))*2-1; math.Abs(v-want) > 1e-12 {
+			t.Fatalf("Billow at %v, %v: %v, expected %v", x, y, v, want)
+		}
+	})
+}
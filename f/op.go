@@ -110,3 +110,173 @@ func (f F) WarpDomain(freq, warp float64) F {
 		return f(x*freq+warpX*warp, y*freq+warpY*warp)
 	}
 }
+
+// Add returns a new F that returns values by the old function plus a value v. The range of the old function is
+// shifted by v.
+func (f F) Add(v float64) F {
+	return func(x, y float64) float64 {
+		return f(x, y) + v
+	}
+}
+
+// AddF returns a new F that returns values of the old function plus the value of the function v at the same x and y
+// values. If the ranges of the functions are [a b] and [c d], the range of the new F is [a+c b+d].
+func (f F) AddF(v F) F {
+	return func(x, y float64) float64 {
+		return f(x, y) + v(x, y)
+	}
+}
+
+// Sub returns a new F that returns values by the old function minus a value v. The range of the old function is
+// shifted by -v.
+func (f F) Sub(v float64) F {
+	return func(x, y float64) float64 {
+		return f(x, y) - v
+	}
+}
+
+// SubF returns a new F that returns values of the old function minus the value of the function v at the same x and
+// y values. If the ranges of the functions are [a b] and [c d], the range of the new F is [a-d b-c].
+func (f F) SubF(v F) F {
+	return func(x, y float64) float64 {
+		return f(x, y) - v(x, y)
+	}
+}
+
+// Div returns a new F that returns values by the old function divided by a value v. The range of the old function is
+// scaled by 1/v.
+func (f F) Div(v float64) F {
+	return func(x, y float64) float64 {
+		return f(x, y) / v
+	}
+}
+
+// DivF returns a new F that returns values of the old function divided by the value of the function v at the same x
+// and y values. The range of the new F is unbounded if v may return values close to 0, and where v returns 0, the
+// new F returns ±Inf or NaN.
+func (f F) DivF(v F) F {
+	return func(x, y float64) float64 {
+		return f(x, y) / v(x, y)
+	}
+}
+
+// Min returns a new F that returns the smallest of the values of the functions passed. If the ranges of the
+// functions are [a b] and [c d], the range of the new F is [min(a, c) min(b, d)]. Min panics if no functions are
+// passed.
+func Min(f ...F) F {
+	if len(f) == 0 {
+		panic("f: min of no functions")
+	}
+	if len(f) == 1 {
+		return f[0]
+	}
+	return func(x, y float64) float64 {
+		v := f[0](x, y)
+		for _, fu := range f[1:] {
+			v = math.Min(v, fu(x, y))
+		}
+		return v
+	}
+}
+
+// Max returns a new F that returns the largest of the values of the functions passed. If the ranges of the
+// functions are [a b] and [c d], the range of the new F is [max(a, c) max(b, d)]. Max panics if no functions are
+// passed.
+func Max(f ...F) F {
+	if len(f) == 0 {
+		panic("f: max of no functions")
+	}
+	if len(f) == 1 {
+		return f[0]
+	}
+	return func(x, y float64) float64 {
+		v := f[0](x, y)
+		for _, fu := range f[1:] {
+			v = math.Max(v, fu(x, y))
+		}
+		return v
+	}
+}
+
+// Clamp returns a new F that returns values by the old function limited to the range [min max].
+func (f F) Clamp(min, max float64) F {
+	return func(x, y float64) float64 {
+		return math.Max(min, math.Min(max, f(x, y)))
+	}
+}
+
+// Lerp returns an F that linearly interpolates between the values of a and b, using the value of t as the weight of
+// b. For values of t in the range [0 1], the values returned lie between those of a and b. Values of t outside of
+// that range extrapolate.
+func Lerp(a, b, t F) F {
+	return func(x, y float64) float64 {
+		return lerp(a(x, y), b(x, y), t(x, y))
+	}
+}
+
+// Select returns an F that returns the value of b where the value of control exceeds the threshold passed and the
+// value of a where it does not. Within falloff of the threshold, the values of a and b are blended smoothly to avoid
+// sharp edges. A falloff of 0 results in a hard transition. The values returned lie between those of a and b.
+func Select(control, a, b F, threshold, falloff float64) F {
+	return func(x, y float64) float64 {
+		c := control(x, y)
+		switch {
+		case c <= threshold-falloff:
+			return a(x, y)
+		case c > threshold+falloff:
+			return b(x, y)
+		}
+		t := (c - (threshold - falloff)) / (2 * falloff)
+		return lerp(a(x, y), b(x, y), t*t*(3-2*t))
+	}
+}
+
+// Terrace returns a new F that maps the values of the old function to steps, creating flat terraces with steep
+// slopes between them. Every interval of 1/steps is mapped to its lower bound, so values in the range [0 1) are mapped
+// to the values 0, 1/steps, 2/steps, ..., (steps-1)/steps. The range of the old function is otherwise preserved.
+// Terrace panics if steps is less than 1.
+func (f F) Terrace(steps int) F {
+	if steps < 1 {
+		panic("f: terrace must have at least 1 step")
+	}
+	s := float64(steps)
+	return func(x, y float64) float64 {
+		return math.Floor(f(x, y)*s) / s
+	}
+}
+
+// Translate returns a new F that is the old function moved by dx and dy, so that the value of the new F at x, y is
+// that of the old function at x-dx, y-dy. The range of the old function is preserved.
+func (f F) Translate(dx, dy float64) F {
+	return func(x, y float64) float64 {
+		return f(x-dx, y-dy)
+	}
+}
+
+// Rotate returns a new F that is the old function rotated counter-clockwise around the origin by an angle in radians.
+// The range of the old function is preserved.
+func (f F) Rotate(angle float64) F {
+	sin, cos := math.Sincos(-angle)
+	return func(x, y float64) float64 {
+		return f(x*cos-y*sin, x*sin+y*cos)
+	}
+}
+
+// Scale returns a new F with frequency sx along the x axis and frequency sy along the y axis. Unlike Freq, Scale may
+// stretch the old function in one direction. The range of the old function is preserved.
+func (f F) Scale(sx, sy float64) F {
+	return func(x, y float64) float64 {
+		return f(x*sx, y*sy)
+	}
+}
+
+// Turbulence returns a new F that randomly displaces the x and y that the old function is evaluated at. The
+// displacement is computed from noise with the seed and frequency passed, and is at most power blocks in either
+// direction. The range of the old function is preserved.
+func (f F) Turbulence(seed int64, freq, power float64) F {
+	dx := Fractal{Seed: seed, Octaves: 3, Frequency: freq}.F()
+	dy := Fractal{Seed: seed + 1, Octaves: 3, Frequency: freq}.F()
+	return func(x, y float64) float64 {
+		return f(x+dx(x, y)*power, y+dy(x, y)*power)
+	}
+}
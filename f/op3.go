@@ -0,0 +1,126 @@
+package f
+
+import (
+	"math"
+)
+
+// F3 is a three-dimensional function used in the f package. It is the counterpart of F for volumes rather than
+// planes: The y passed to an F3 is the vertical axis, whereas the x and z correspond to the x and y of an F. Like F, it
+// may be manipulated by any of the methods it has, resulting in a new F3 being created.
+type F3 func(x, y, z float64) float64
+
+// Sum3 sums up multiple functions and returns a new F3 that is the result of adding up the functions.
+func Sum3(f ...F3) F3 {
+	if len(f) == 1 {
+		return f[0]
+	}
+	return func(x, y, z float64) float64 {
+		var i float64
+		for _, fu := range f {
+			i += fu(x, y, z)
+		}
+		return i
+	}
+}
+
+// F3 returns an F3 that returns the value of the function at x and z for any y, extruding the plane of the F
+// vertically.
+func (f F) F3() F3 {
+	return func(x, y, z float64) float64 {
+		return f(x, z)
+	}
+}
+
+// Slice returns an F that returns the values of the old function in the horizontal plane at height y.
+func (f F3) Slice(y float64) F {
+	return func(x, z float64) float64 {
+		return f(x, y, z)
+	}
+}
+
+// Norm normalises the results of the old function and returns a new F3 that returns values in the range [0 1) as
+// opposed to (-1 1).
+func (f F3) Norm() F3 {
+	return func(x, y, z float64) float64 {
+		return (f(x, y, z) + 1) / 2
+	}
+}
+
+// Inv inverses the results of the old function and returns a new F3 that has its values [0 1) changed to [1 0).
+func (f F3) Inv() F3 {
+	return func(x, y, z float64) float64 {
+		return 1 - f(x, y, z)
+	}
+}
+
+// Pow returns a new F3 that raises values returned by the old function to the power of v.
+func (f F3) Pow(v float64) F3 {
+	return func(x, y, z float64) float64 {
+		return math.Pow(f(x, y, z), v)
+	}
+}
+
+// Thresh returns a new F3 that returns 1 for values returned by the function that exceed the threshold v. If they
+// do not exceed the threshold, 0 is returned.
+func (f F3) Thresh(v float64) F3 {
+	return func(x, y, z float64) float64 {
+		if f(x, y, z) > v {
+			return 1
+		}
+		return 0
+	}
+}
+
+// Abs returns a new F3 that only returns absolute values.
+func (f F3) Abs() F3 {
+	return func(x, y, z float64) float64 {
+		return math.Abs(f(x, y, z))
+	}
+}
+
+// Mul returns a new F3 that returns values by the old function multiplied by a value v.
+func (f F3) Mul(v float64) F3 {
+	return func(x, y, z float64) float64 {
+		return f(x, y, z) * v
+	}
+}
+
+// Freq returns a new F3 with frequency v.
+func (f F3) Freq(v float64) F3 {
+	return func(x, y, z float64) float64 {
+		return f(x*v, y*v, z*v)
+	}
+}
+
+// MulF returns a new F3 that returns values of the old function multiplied by the value of the function v at the
+// same x, y and z values.
+func (f F3) MulF(v F3) F3 {
+	return func(x, y, z float64) float64 {
+		return f(x, y, z) * v(x, y, z)
+	}
+}
+
+// Slope returns an F3 that returns the approximate magnitude of the gradient at a position. The dx specified
+// influences the distance over which the gradient is calculated.
+func (f F3) Slope(dx float64) F3 {
+	return func(x, y, z float64) float64 {
+		dX := (f(x+dx/2, y, z) - f(x-dx/2, y, z)) / dx
+		dY := (f(x, y+dx/2, z) - f(x, y-dx/2, z)) / dx
+		dZ := (f(x, y, z+dx/2) - f(x, y, z-dx/2)) / dx
+
+		return math.Sqrt(dX*dX + dY*dY + dZ*dZ)
+	}
+}
+
+// WarpDomain returns a new F3 that performs domain warping on the old function, like F.WarpDomain. The frequency
+// passed influences the frequency desired of the F3 returned (practically, this is the zoom level). The warp value
+// specifies the extent to which the warping should happen.
+func (f F3) WarpDomain(freq, warp float64) F3 {
+	i, j, k, l, m, n := 0.0, 0.0, 5.3, 1.3, 9.2, 2.8
+	return func(x, y, z float64) float64 {
+		warpX := f(x*freq+i, y*freq+j, z*freq)
+		warpY := f(x*freq+k, y*freq, z*freq+l)
+		warpZ := f(x*freq, y*freq+m, z*freq+n)
+		return f(x*freq+warpX*warp, y*freq+warpY*warp, z*freq+warpZ*warp)
+	}
+}
@@ -0,0 +1,222 @@
+package f
+
+import (
+	"math"
+	"testing"
+)
+
+// testGrid calls fn for a grid of positions spread over an area much larger than a single period of the noise used
+// in tests.
+func testGrid(fn func(x, y float64)) {
+	for x := -500.0; x < 500; x += 7.3 {
+		for y := -500.0; y < 500; y += 11.1 {
+			fn(x, y)
+		}
+	}
+}
+
+// posX and posY are functions that return the x and y that they are evaluated at, used to test transformations of
+// the position that a function is evaluated at.
+var (
+	posX F = func(x, y float64) float64 { return x }
+	posY F = func(x, y float64) float64 { return y }
+)
+
+// inRange checks if v is in the range [min max].
+func inRange(v, min, max float64) bool {
+	return v >= min && v <= max
+}
+
+// expectPanic fails the test if fn does not panic.
+func expectPanic(t *testing.T, name string, fn func()) {
+	t.Helper()
+	defer func() {
+		if recover() == nil {
+			t.Errorf("%v did not panic", name)
+		}
+	}()
+	fn()
+}
+
+func TestArithmetic(t *testing.T) {
+	a, b := Noise(1, 3, 2, 0.5), Noise(2, 3, 2, 0.5).Norm()
+	for _, test := range []struct {
+		name     string
+		f        F
+		want     func(a, b float64) float64
+		min, max float64
+	}{
+		{"Add", a.Add(2), func(a, b float64) float64 { return a + 2 }, 1, 3},
+		{"AddF", a.AddF(b), func(a, b float64) float64 { return a + b }, -1, 2},
+		{"Sub", a.Sub(2), func(a, b float64) float64 { return a - 2 }, -3, -1},
+		{"SubF", a.SubF(b), func(a, b float64) float64 { return a - b }, -2, 1},
+		{"Div", a.Div(4), func(a, b float64) float64 { return a / 4 }, -0.25, 0.25},
+		{"DivF", a.DivF(b.Add(1)), func(a, b float64) float64 { return a / (b + 1) }, -1, 1},
+	} {
+		testGrid(func(x, y float64) {
+			v := test.f(x, y)
+			if want := test.want(a(x, y), b(x, y)); v != want {
+				t.Fatalf("%v at %v, %v: %v, expected %v", test.name, x, y, v, want)
+			}
+			if !inRange(v, test.min, test.max) {
+				t.Fatalf("%v at %v, %v: %v outside of range [%v %v]", test.name, x, y, v, test.min, test.max)
+			}
+		})
+	}
+	if v := Const(1).DivF(Const(0))(0, 0); !math.IsInf(v, 1) {
+		t.Errorf("DivF by 0: %v, expected +Inf", v)
+	}
+}
+
+func TestMinMax(t *testing.T) {
+	a, b, c := Noise(1, 3, 2, 0.5), Noise(2, 3, 2, 0.5), Const(0.1)
+	minF, maxF := Min(a, b, c), Max(a, b, c)
+	testGrid(func(x, y float64) {
+		va, vb, vc := a(x, y), b(x, y), c(x, y)
+		if v, want := minF(x, y), math.Min(va, math.Min(vb, vc)); v != want {
+			t.Fatalf("Min at %v, %v: %v, expected %v", x, y, v, want)
+		}
+		if v, want := maxF(x, y), math.Max(va, math.Max(vb, vc)); v != want {
+			t.Fatalf("Max at %v, %v: %v, expected %v", x, y, v, want)
+		}
+		if v := minF(x, y); !inRange(v, -1, 0.1) {
+			t.Fatalf("Min at %v, %v: %v outside of range [-1 0.1]", x, y, v)
+		}
+		if v := maxF(x, y); !inRange(v, 0.1, 1) {
+			t.Fatalf("Max at %v, %v: %v outside of range [0.1 1]", x, y, v)
+		}
+		if Min(a)(x, y) != va || Max(a)(x, y) != va {
+			t.Fatalf("Min or Max of a single function at %v, %v: not %v", x, y, va)
+		}
+	})
+	expectPanic(t, "Min()", func() { Min() })
+	expectPanic(t, "Max()", func() { Max() })
+}
+
+func TestClamp(t *testing.T) {
+	n := Noise(1, 3, 2, 0.5)
+	clamped := n.Clamp(-0.2, 0.3)
+	testGrid(func(x, y float64) {
+		v, nv := clamped(x, y), n(x, y)
+		if !inRange(v, -0.2, 0.3) {
+			t.Fatalf("Clamp at %v, %v: %v outside of range [-0.2 0.3]", x, y, v)
+		}
+		if inRange(nv, -0.2, 0.3) && v != nv {
+			t.Fatalf("Clamp at %v, %v: %v, expected unchanged value %v", x, y, v, nv)
+		}
+	})
+}
+
+func TestLerp(t *testing.T) {
+	a, b, w := Noise(1, 3, 2, 0.5), Noise(2, 3, 2, 0.5).Add(3), Noise(3, 3, 2, 0.5).Norm()
+	l := Lerp(a, b, w)
+	testGrid(func(x, y float64) {
+		va, vb := a(x, y), b(x, y)
+		if v := Lerp(a, b, Const(0))(x, y); v != va {
+			t.Fatalf("Lerp with t 0 at %v, %v: %v, expected %v", x, y, v, va)
+		}
+		if v := Lerp(a, b, Const(1))(x, y); math.Abs(v-vb) > 1e-12 {
+			t.Fatalf("Lerp with t 1 at %v, %v: %v, expected %v", x, y, v, vb)
+		}
+		if v := l(x, y); !inRange(v, va, vb) {
+			t.Fatalf("Lerp at %v, %v: %v outside of range [%v %v]", x, y, v, va, vb)
+		}
+	})
+}
+
+func TestSelect(t *testing.T) {
+	a, b := Const(-1), Const(2)
+	for _, test := range []struct {
+		control, falloff, want float64
+	}{
+		{0, 0.1, -1},
+		{0.39, 0.1, -1},
+		{0.4, 0.1, -1},
+		{0.5, 0.1, 0.5},
+		{0.61, 0.1, 2},
+		{1, 0.1, 2},
+		{0.5, 0, -1},
+		{0.500001, 0, 2},
+	} {
+		if v := Select(Const(test.control), a, b, 0.5, test.falloff)(0, 0); math.Abs(v-test.want) > 1e-12 {
+			t.Errorf("Select with control %v and falloff %v: %v, expected %v", test.control, test.falloff, v, test.want)
+		}
+	}
+	s := Select(Noise(1, 3, 2, 0.5).Norm(), Noise(2, 3, 2, 0.5), Noise(3, 3, 2, 0.5).Add(3), 0.5, 0.1)
+	testGrid(func(x, y float64) {
+		if v := s(x, y); !inRange(v, -1, 4) {
+			t.Fatalf("Select at %v, %v: %v outside of range [-1 4]", x, y, v)
+		}
+	})
+}
+
+func TestTerrace(t *testing.T) {
+	n := Noise(1, 3, 2, 0.5).Norm()
+	terraced := n.Terrace(4)
+	testGrid(func(x, y float64) {
+		v, nv := terraced(x, y), n(x, y)
+		if v != 0 && v != 0.25 && v != 0.5 && v != 0.75 {
+			t.Fatalf("Terrace at %v, %v: %v is not a step", x, y, v)
+		}
+		if v > nv || nv-v >= 0.25 {
+			t.Fatalf("Terrace at %v, %v: %v is not the step below %v", x, y, v, nv)
+		}
+	})
+	if v := Const(-0.1).Terrace(4)(0, 0); v != -0.25 {
+		t.Errorf("Terrace of -0.1: %v, expected -0.25", v)
+	}
+	expectPanic(t, "Terrace(0)", func() { n.Terrace(0) })
+	expectPanic(t, "Terrace(-1)", func() { n.Terrace(-1) })
+}
+
+func TestTransform(t *testing.T) {
+	n := Noise(1, 3, 2, 0.5)
+	testGrid(func(x, y float64) {
+		if v, want := n.Translate(10, -20)(x, y), n(x-10, y+20); v != want {
+			t.Fatalf("Translate at %v, %v: %v, expected %v", x, y, v, want)
+		}
+		if v, want := n.Scale(2, 0.5)(x, y), n(x*2, y*0.5); v != want {
+			t.Fatalf("Scale at %v, %v: %v, expected %v", x, y, v, want)
+		}
+		// Rotating by a full turn leaves the function unchanged, apart from rounding errors.
+		if v, want := n.Rotate(2*math.Pi)(x, y), n(x, y); math.Abs(v-want) > 1e-9 {
+			t.Fatalf("Rotate by 2π at %v, %v: %v, expected %v", x, y, v, want)
+		}
+		// Rotating a quarter turn counter-clockwise moves the value at x, y to -y, x.
+		if v, want := n.Rotate(math.Pi/2)(-y, x), n(x, y); math.Abs(v-want) > 1e-9 {
+			t.Fatalf("Rotate by π/2 at %v, %v: %v, expected %v", -y, x, v, want)
+		}
+		for _, f := range []F{n.Translate(10, -20), n.Scale(2, 0.5), n.Rotate(1)} {
+			if v := f(x, y); !inRange(v, -1, 1) {
+				t.Fatalf("transformed function at %v, %v: %v outside of range [-1 1]", x, y, v)
+			}
+		}
+	})
+	if v := posX.Rotate(math.Pi/2)(0, 1); math.Abs(v-1) > 1e-12 {
+		t.Errorf("x rotated by π/2 at 0, 1: %v, expected 1", v)
+	}
+}
+
+func TestTurbulence(t *testing.T) {
+	const power = 8
+	tx, ty := posX.Turbulence(1, 0.1, power), posY.Turbulence(1, 0.1, power)
+	var displaced bool
+	testGrid(func(x, y float64) {
+		dx, dy := tx(x, y)-x, ty(x, y)-y
+		if math.Abs(dx) > power || math.Abs(dy) > power {
+			t.Fatalf("Turbulence at %v, %v: displaced by %v, %v, expected at most %v", x, y, dx, dy, power)
+		}
+		if dx != 0 || dy != 0 {
+			displaced = true
+		}
+	})
+	if !displaced {
+		t.Errorf("Turbulence did not displace any position")
+	}
+	n := Noise(1, 3, 2, 0.5).Turbulence(1, 0.1, power)
+	testGrid(func(x, y float64) {
+		if v := n(x, y); !inRange(v, -1, 1) {
+			t.Fatalf("Turbulence at %v, %v: %v outside of range [-1 1]", x, y, v)
+		}
+	})
+}
@@ -0,0 +1,59 @@
+package f
+
+import (
+	"math"
+)
+
+// Perlin returns a function that may be used to calculate layered gradient (Perlin) noise at a specific x and y. Its
+// values are in the range (-1 1). The seed, octaves, lacunarity and persistence have the same meaning as for Noise.
+// Unlike the simplex noise of Noise, Perlin noise is aligned to a square grid, which may show in the terrain as
+// features along the x and y axes.
+func Perlin(seed int64, octaves int, lacunarity, persistence float64) F {
+	return layered(octaves, lacunarity, persistence, func(x, y float64) float64 {
+		x0, y0 := math.Floor(x), math.Floor(y)
+		fx, fy := x-x0, y-y0
+		ix, iy := int64(x0), int64(y0)
+
+		u, v := fade(fx), fade(fy)
+		a := lerp(gradient(seed, ix, iy, fx, fy), gradient(seed, ix+1, iy, fx-1, fy), u)
+		b := lerp(gradient(seed, ix, iy+1, fx, fy-1), gradient(seed, ix+1, iy+1, fx-1, fy-1), u)
+		return lerp(a, b, v)
+	})
+}
+
+// Value returns a function that may be used to calculate layered value noise at a specific x and y. Value noise
+// interpolates random values assigned to the points of a square grid and returns values in the range [-1 1). The seed,
+// octaves, lacunarity and persistence have the same meaning as for Noise. Value noise is blockier than gradient
+// noise, which makes it suitable for plateaus and patches rather than hills.
+func Value(seed int64, octaves int, lacunarity, persistence float64) F {
+	return layered(octaves, lacunarity, persistence, func(x, y float64) float64 {
+		x0, y0 := math.Floor(x), math.Floor(y)
+		ix, iy := int64(x0), int64(y0)
+
+		u, v := fade(x-x0), fade(y-y0)
+		a := lerp(unit(hash2(seed, ix, iy)), unit(hash2(seed, ix+1, iy)), u)
+		b := lerp(unit(hash2(seed, ix, iy+1)), unit(hash2(seed, ix+1, iy+1)), u)
+		return lerp(a, b, v)
+	})
+}
+
+// gradients holds the gradient vectors that are assigned to the points of the grid of Perlin noise. All gradients have
+// a length of √2, so that the noise spans the full range (-1 1).
+var gradients = [8][2]float64{{1, 1}, {-1, 1}, {1, -1}, {-1, -1}, {math.Sqrt2, 0}, {-math.Sqrt2, 0}, {0, math.Sqrt2}, {0, -math.Sqrt2}}
+
+// gradient returns the dot product of the gradient at grid point ix, iy and the offset dx, dy from that point.
+func gradient(seed, ix, iy int64, dx, dy float64) float64 {
+	g := gradients[hash2(seed, ix, iy)&7]
+	return g[0]*dx + g[1]*dy
+}
+
+// fade eases a value t in the range [0 1] with the quintic curve 6t^5 - 15t^4 + 10t^3, so that the interpolation
+// between grid points is smooth.
+func fade(t float64) float64 {
+	return t * t * t * (t*(t*6-15) + 10)
+}
+
+// lerp linearly interpolates between a and b by t.
+func lerp(a, b, t float64) float64 {
+	return a + (b-a)*t
+}
@@ -0,0 +1,42 @@
+package f
+
+import (
+	"github.com/ojrac/opensimplex-go"
+	"math"
+)
+
+// Ridged returns a function that may be used to calculate ridged multifractal noise at a specific x and y. Every
+// octave is simplex noise folded along its zero crossings, producing sharp ridges, and weighted by the octave before
+// it, so that detail is concentrated on the ridges while valleys stay smooth. Its values are in the range [-1 1], with
+// the ridges close to 1. The seed, octaves, lacunarity and persistence have the same meaning as for Noise.
+func Ridged(seed int64, octaves int, lacunarity, persistence float64) F {
+	n := opensimplex.New(seed)
+
+	var max float64
+	for i := 0; i < octaves; i++ {
+		max += math.Pow(persistence, float64(i))
+	}
+	return func(x, y float64) float64 {
+		v, weight := 0.0, 1.0
+		for i := 0; i < octaves; i++ {
+			freq := math.Pow(lacunarity, float64(i)) * 0.04
+			amp := math.Pow(persistence, float64(i))
+
+			s := 1 - math.Abs(n.Eval2(x*freq, y*freq))
+			s *= s * weight
+			weight = math.Min(math.Max(s*2, 0), 1)
+			v += amp * s
+		}
+		return v/max*2 - 1
+	}
+}
+
+// Billow returns a function that may be used to calculate billow noise at a specific x and y. Every octave is the
+// absolute value of simplex noise, producing rounded, puffy shapes like clouds or rolling hills. Its values are in the
+// range [-1 1]. The seed, octaves, lacunarity and persistence have the same meaning as for Noise.
+func Billow(seed int64, octaves int, lacunarity, persistence float64) F {
+	n := opensimplex.New(seed)
+	return layered(octaves, lacunarity, persistence, func(x, y float64) float64 {
+		return math.Abs(n.Eval2(x, y))*2 - 1
+	})
+}
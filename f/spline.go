@@ -0,0 +1,94 @@
+package f
+
+import (
+	"sort"
+)
+
+// Const returns an F that returns v for every x and y.
+func Const(v float64) F {
+	return func(x, y float64) float64 {
+		return v
+	}
+}
+
+// SplinePoint is a control point of a spline created using F.Spline or F.LinearSpline.
+type SplinePoint struct {
+	// Location is the value of the input function at which the spline passes through the point.
+	Location float64
+	// Value is the function that returns the value of the spline at the point. It is evaluated at the same x and y
+	// as the input function, so that splines may be nested by passing the result of another call to F.Spline. Use
+	// Const for a fixed value.
+	Value F
+	// Derivative is the slope of the spline at the point. It is only used by F.Spline.
+	Derivative float64
+}
+
+// Spline returns a new F that remaps the values of the old function through a cubic Hermite spline passing through
+// the control points passed, with the slope at every point specified by its Derivative. Values beyond the first and
+// last point are extrapolated linearly using the Derivative of that point. Spline panics if no points are passed.
+//
+// An example that turns continentalness in the range [0 1) into an elevation that is low below 0.4, rises steeply
+// at the coast and is hilly inland:
+//
+//	cont.Spline(
+//		f.SplinePoint{Location: 0, Value: f.Const(0.05)},
+//		f.SplinePoint{Location: 0.4, Value: f.Const(0.1), Derivative: 0.5},
+//		f.SplinePoint{Location: 0.5, Value: f.Const(0.3), Derivative: 0.5},
+//		f.SplinePoint{Location: 1, Value: hills},
+//	)
+func (f F) Spline(points ...SplinePoint) F {
+	points = sortedPoints(points)
+	return func(x, y float64) float64 {
+		v := f(x, y)
+		i := sort.Search(len(points), func(i int) bool { return points[i].Location > v })
+		if i == 0 {
+			p := points[0]
+			return p.Value(x, y) + (v-p.Location)*p.Derivative
+		}
+		if i == len(points) {
+			p := points[len(points)-1]
+			return p.Value(x, y) + (v-p.Location)*p.Derivative
+		}
+		a, b := points[i-1], points[i]
+		dx := b.Location - a.Location
+		t := (v - a.Location) / dx
+
+		// Cubic Hermite basis functions, with the derivatives scaled to the width of the interval.
+		t2, t3 := t*t, t*t*t
+		h00 := 2*t3 - 3*t2 + 1
+		h10 := t3 - 2*t2 + t
+		h01 := -2*t3 + 3*t2
+		h11 := t3 - t2
+		return h00*a.Value(x, y) + h10*dx*a.Derivative + h01*b.Value(x, y) + h11*dx*b.Derivative
+	}
+}
+
+// LinearSpline returns a new F that remaps the values of the old function through a piecewise linear spline passing
+// through the control points passed. The Derivative of the points is ignored. Values beyond the first and last point
+// are mapped to the value of that point. LinearSpline panics if no points are passed.
+func (f F) LinearSpline(points ...SplinePoint) F {
+	points = sortedPoints(points)
+	return func(x, y float64) float64 {
+		v := f(x, y)
+		i := sort.Search(len(points), func(i int) bool { return points[i].Location > v })
+		if i == 0 {
+			return points[0].Value(x, y)
+		}
+		if i == len(points) {
+			return points[len(points)-1].Value(x, y)
+		}
+		a, b := points[i-1], points[i]
+		return lerp(a.Value(x, y), b.Value(x, y), (v-a.Location)/(b.Location-a.Location))
+	}
+}
+
+// sortedPoints returns a copy of the SplinePoints passed sorted by their Location. It panics if no points are passed.
+func sortedPoints(points []SplinePoint) []SplinePoint {
+	if len(points) == 0 {
+		panic("f: spline must have at least one point")
+	}
+	sorted := make([]SplinePoint, len(points))
+	copy(sorted, points)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].Location < sorted[j].Location })
+	return sorted
+}
@@ -0,0 +1,119 @@
+package f
+
+import (
+	"math"
+	"testing"
+)
+
+func TestSpline(t *testing.T) {
+	// The points are passed out of order, to check that they are sorted by their Location.
+	s := posX.Spline(
+		SplinePoint{Location: 1, Value: Const(3), Derivative: -1},
+		SplinePoint{Location: 0, Value: Const(1), Derivative: 2},
+		SplinePoint{Location: 2, Value: posY, Derivative: 0.5},
+	)
+	for _, test := range []struct {
+		x, y, want float64
+	}{
+		// The spline passes through every point.
+		{0, 0, 1},
+		{1, 0, 3},
+		// The value of a point may be a function itself, which is evaluated at the same x and y.
+		{2, 5, 5},
+		{2, -7, -7},
+		// Beyond the first and last point, the spline is extrapolated linearly with the derivative of that point.
+		{-1, 0, -1},
+		{-3, 0, -5},
+		{4, 5, 6},
+		{12, -7, -2},
+		// Halfway between two points, the value is the mean of both values plus (d0-d1)/8 scaled to the interval.
+		{0.5, 0, 2 + (2-(-1))/8.0},
+	} {
+		if v := s(test.x, test.y); math.Abs(v-test.want) > 1e-12 {
+			t.Errorf("Spline at %v, %v: %v, expected %v", test.x, test.y, v, test.want)
+		}
+	}
+
+	// The slope of the spline at every point is the Derivative of that point. The curvature differs on both sides of
+	// a point, so the central difference is only accurate up to the step size.
+	for _, p := range []struct{ x, y, slope float64 }{{0, 0, 2}, {1, 0, -1}, {2, 3, 0.5}} {
+		if dx, _ := centralDifference(s, p.x, p.y); math.Abs(dx-p.slope) > 1e-4 {
+			t.Errorf("Spline slope at %v, %v: %v, expected %v", p.x, p.y, dx, p.slope)
+		}
+	}
+
+	// With a derivative of 0 at both points, the spline stays between their values.
+	flat := Noise(1, 3, 2, 0.5).Spline(
+		SplinePoint{Location: -1, Value: Const(-2)},
+		SplinePoint{Location: 1, Value: Const(4)},
+	)
+	testGrid(func(x, y float64) {
+		if v := flat(x, y); !inRange(v, -2, 4) {
+			t.Fatalf("Spline at %v, %v: %v outside of range [-2 4]", x, y, v)
+		}
+	})
+	if v := posX.Spline(SplinePoint{Location: 1, Value: Const(7), Derivative: 2})(3, 0); v != 11 {
+		t.Errorf("Spline with a single point at 3: %v, expected 11", v)
+	}
+	expectPanic(t, "Spline()", func() { posX.Spline() })
+}
+
+func TestSplineNested(t *testing.T) {
+	// A spline nested as the value of a point of another spline is evaluated at the same x and y, so that the outer
+	// spline follows the inner one at that point.
+	inner := posY.Spline(
+		SplinePoint{Location: 0, Value: Const(0), Derivative: 1},
+		SplinePoint{Location: 10, Value: Const(10), Derivative: 1},
+	)
+	outer := posX.Spline(
+		SplinePoint{Location: 0, Value: Const(-1)},
+		SplinePoint{Location: 1, Value: inner},
+	)
+	for _, y := range []float64{-5, 0, 2.5, 10, 20} {
+		if v, want := outer(1, y), inner(1, y); math.Abs(v-want) > 1e-12 {
+			t.Errorf("nested Spline at 1, %v: %v, expected %v", y, v, want)
+		}
+		if v := outer(0, y); v != -1 {
+			t.Errorf("nested Spline at 0, %v: %v, expected -1", y, v)
+		}
+	}
+}
+
+func TestLinearSpline(t *testing.T) {
+	s := posX.LinearSpline(
+		SplinePoint{Location: 1, Value: Const(3), Derivative: 100},
+		SplinePoint{Location: 0, Value: Const(1)},
+		SplinePoint{Location: 2, Value: posY},
+	)
+	for _, test := range []struct {
+		x, y, want float64
+	}{
+		{0, 0, 1},
+		{0.25, 0, 1.5},
+		{1, 0, 3},
+		// Between two points, the spline interpolates linearly, also towards a nested function.
+		{1.5, 5, 4},
+		{1.5, -7, -2},
+		{2, 5, 5},
+		// Beyond the first and last point, the spline takes the value of that point. The Derivative is ignored.
+		{-10, 0, 1},
+		{12, -7, -7},
+	} {
+		if v := s(test.x, test.y); math.Abs(v-test.want) > 1e-12 {
+			t.Errorf("LinearSpline at %v, %v: %v, expected %v", test.x, test.y, v, test.want)
+		}
+	}
+
+	// The values of a linear spline never leave the range of the values of its points.
+	n := Noise(1, 3, 2, 0.5).LinearSpline(
+		SplinePoint{Location: -0.5, Value: Const(0)},
+		SplinePoint{Location: 0, Value: Const(-1)},
+		SplinePoint{Location: 0.5, Value: Noise(2, 3, 2, 0.5).Norm()},
+	)
+	testGrid(func(x, y float64) {
+		if v := n(x, y); !inRange(v, -1, 1) {
+			t.Fatalf("LinearSpline at %v, %v: %v outside of range [-1 1]", x, y, v)
+		}
+	})
+	expectPanic(t, "LinearSpline()", func() { posX.LinearSpline() })
+}
@@ -0,0 +1,62 @@
+package f
+
+import (
+	"math"
+)
+
+// WorleyMode specifies what value Worley noise returns for a position.
+type WorleyMode int
+
+const (
+	// WorleyF1 returns the distance to the nearest feature point. It produces round cells that are dark in their
+	// centres.
+	WorleyF1 WorleyMode = iota
+	// WorleyF2 returns the distance to the second nearest feature point.
+	WorleyF2
+	// WorleyF2F1 returns the difference between the distances to the second nearest and the nearest feature point.
+	// It is close to -1 on the edges between cells, which makes it suitable for networks of ridges or rivers.
+	WorleyF2F1
+	// WorleyCell returns a random value that is the same for all positions in the same cell.
+	WorleyCell
+)
+
+// Worley returns a function that may be used to calculate layered Worley (cellular) noise at a specific x and y. Every
+// cell of a square grid holds one randomly placed feature point, and the WorleyMode passed specifies the value
+// returned based on the feature points nearest to a position. Distances are clamped to [0 1] and mapped to the range
+// [-1 1], so that all modes return values in the range [-1 1]. The seed, octaves, lacunarity and persistence have the
+// same meaning as for Noise.
+func Worley(seed int64, octaves int, lacunarity, persistence float64, mode WorleyMode) F {
+	return layered(octaves, lacunarity, persistence, func(x, y float64) float64 {
+		x0, y0 := math.Floor(x), math.Floor(y)
+		ix, iy := int64(x0), int64(y0)
+
+		f1, f2 := math.Inf(1), math.Inf(1)
+		var cell uint64
+		for cx := ix - 1; cx <= ix+1; cx++ {
+			for cy := iy - 1; cy <= iy+1; cy++ {
+				h := hash2(seed, cx, cy)
+				// The lower and upper 32 bits of the hash position the feature point within its cell.
+				px := float64(cx) + float64(h&0xffffffff)/(1<<32)
+				py := float64(cy) + float64(h>>32)/(1<<32)
+
+				dx, dy := px-x, py-y
+				d := math.Sqrt(dx*dx + dy*dy)
+				if d < f1 {
+					f1, f2, cell = d, f1, h
+				} else if d < f2 {
+					f2 = d
+				}
+			}
+		}
+		switch mode {
+		case WorleyF1:
+			return math.Min(f1, 1)*2 - 1
+		case WorleyF2:
+			return math.Min(f2, 1)*2 - 1
+		case WorleyF2F1:
+			return math.Min(f2-f1, 1)*2 - 1
+		default:
+			return unit(hash2(seed, int64(cell), 0))
+		}
+	})
+}
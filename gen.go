@@ -10,77 +10,213 @@ import (
 	"time"
 )
 
+// Generator is a world.Generator that generates terrain with biomes laid out in a voronoi diagram. A Generator is safe
+// for concurrent use: GenerateChunk may be called from multiple goroutines at the same time, as long as every call is
+// passed a different chunk.Chunk.
 type Generator struct {
-	temp, hum    f.F
-	blurX, blurZ f.F
-	b            biomeSet
+	conf          Config
+	temp, hum     f.F
+	cont, erosion f.F
+	weirdness     f.F
+	elevation     f.F
+	blurX, blurZ  f.F
+	biomes        *BiomeRegistry
+	cache         *cellCache
+	caves         *caveCarver
+	ores          []ore
 }
 
-// New creates a new Generator that implements world.Generator.
+// New creates a new Generator that implements world.Generator. The Generator is seeded using the current time, so
+// the terrain it produces is different every time. Use NewWithConfig to create a Generator with a fixed seed.
 func New() *Generator {
-	seed := time.Now().Unix()
+	return NewWithConfig(Config{Seed: time.Now().Unix()})
+}
+
+// NewWithConfig creates a new Generator that implements world.Generator using the Config passed. All noise used by
+// the Generator is derived from Config.Seed, so that the same Config always produces the same terrain.
+func NewWithConfig(conf Config) *Generator {
+	conf = conf.withDefaults()
+	if conf.Biomes == nil || len(conf.Biomes.biomes) == 0 {
+		conf.Biomes = DefaultBiomes(conf)
+	}
+	seed := conf.Seed
+	// Continentalness changes at half the frequency of the other climate parameters, so that oceans and continents
+	// are large and coherent.
+	cont := f.Noise(seed+0xf0f, 2, 2, 0.5).Norm().Freq(0.5)
+	// The elevation added to the height of every Biome drops with the continentalness far out at sea and rises far
+	// inland. It is 0 around the coast, where ocean and land biomes meet, so that neither is pushed across the sea
+	// level.
+	elevation := cont.Freq(conf.ClimateFrequency).Spline(
+		f.SplinePoint{Location: 0.25, Value: f.Const(-0.08)},
+		f.SplinePoint{Location: 0.38, Value: f.Const(0)},
+		f.SplinePoint{Location: 0.5, Value: f.Const(0)},
+		f.SplinePoint{Location: 0.7, Value: f.Const(0.12)},
+	)
+	ores := make([]ore, len(conf.Ores))
+	for i, o := range conf.Ores {
+		ores[i] = newOre(o)
+	}
 	return &Generator{
-		blurX: f.Noise(seed+0x00f, 4, 2, 0.5).Norm(),
-		blurZ: f.Noise(seed+0x0ff, 4, 2, 0.5).Norm(),
-		temp:  f.Noise(seed+0x0f0, 1, 2, 1).Norm(),
-		hum:   f.Noise(seed+0xf00, 1, 2, 1).Norm(),
-		b:     newBiomeSet(seed),
+		conf:      conf,
+		blurX:     f.Noise(seed+0x00f, 4, 2, 0.5).Norm(),
+		blurZ:     f.Noise(seed+0x0ff, 4, 2, 0.5).Norm(),
+		temp:      f.Noise(seed+0x0f0, 1, 2, 1).Norm(),
+		hum:       f.Noise(seed+0xf00, 1, 2, 1).Norm(),
+		cont:      cont,
+		erosion:   f.Noise(seed+0xff0, 2, 2, 0.5).Norm(),
+		weirdness: f.Noise(seed+0xfff, 2, 2, 0.5).Norm(),
+		elevation: elevation,
+		biomes:    conf.Biomes,
+		cache:     newCellCache(conf.CacheSize),
+		caves:     newCaveCarver(seed),
+		ores:      ores,
 	}
 }
 
 // GenerateChunk generates a chunk.Chunk at a world.ChunkPos in the world.
 func (g *Generator) GenerateChunk(pos world.ChunkPos, chunk *chunk.Chunk) {
-	m := calculateTerrainMap(10, pos, g, chunk).smooth(10, normalCurve)
+	r := g.conf.SmoothingRadius
+	// The terrain map extends beyond the chunk, so that features rooted in neighbouring chunks can be placed too.
+	decorated := calculateTerrainMap(r+decorationMargin, pos, g, chunk).smooth(r, normalCurve)
+	m := decorated.inner(decorationMargin)
 
+	ra := chunk.Range()
+	// In density mode, the surface of a column is its highest solid block rather than the height of its Biome.
+	surface := m
+	if g.conf.Density != nil {
+		decorated = g.densitySurface(pos, decorated, decorationMargin, ra)
+		surface = decorated.inner(decorationMargin)
+	}
+	s, minY := g.strata(ra), ra.Min()
+	seaLevel := g.conf.SeaLevel
+	if seaLevel > ra.Max() {
+		seaLevel = ra.Max()
+	}
+
+	var heights [256]int
 	baseX, baseZ := pos[0]<<4, pos[1]<<4
 	for x := uint8(0); x < 16; x++ {
 		for z := uint8(0); z < 16; z++ {
 			col := m[x+z*16]
+			absX, absZ := int(baseX)+int(x), int(baseZ)+int(z)
 
-			for y := int16(0); y <= int16(col.height); y++ {
-				chunk.SetBlock(x, y, z, 0, stone)
+			// Make sure the height of the column fits in the vertical range of the chunk.
+			height := int(surface[x+z*16].height)
+			if height < ra.Min() {
+				height = ra.Min()
+			} else if height > ra.Max() {
+				height = ra.Max()
+			}
+			heights[x+z*16] = height
+
+			// Blocks from hollowY up may be hollow in density mode, so that terrain can overhang.
+			hollowY := height + 1
+			if g.conf.Density != nil {
+				if hollowY, _ = g.densityBand(col.height); hollowY < minY {
+					hollowY = minY
+				}
+			}
+			for y := minY; y <= height; y++ {
+				if y >= hollowY && !g.solid(absX, y, absZ, col.height) {
+					continue
+				}
+				chunk.SetBlock(x, int16(y), z, 0, g.rock(s, minY, absX, y, absZ))
+			}
+			col.biome.CoverGround(x, z, baseX+int32(x), baseZ+int32(z), height, chunk)
+
+			// Fill up all air between the surface and the sea level with water.
+			for y := hollowY; y <= seaLevel; y++ {
+				if chunk.Block(x, int16(y), z, 0) == air {
+					chunk.SetBlock(x, int16(y), z, 0, water)
+				}
+			}
+
+			// Biomes are stored per block in every sub chunk, so we write the biome for the full column. Below the
+			// surface, underground biomes may replace the biome of the column. They are not laid out in voronoi
+			// cells, so they are selected using the climate of the column itself.
+			id := col.biome.ID()
+			var climate Climate
+			if g.biomes.underground {
+				climate = g.climate(float64(absX), float64(absZ))
+			}
+			for y := ra.Min(); y <= ra.Max(); y++ {
+				climate.Depth = float64(height - y)
+				if b, ok := g.biomes.selectUnderground(climate); ok {
+					chunk.SetBiome(x, int16(y), z, b.ID())
+					continue
+				}
+				chunk.SetBiome(x, int16(y), z, id)
 			}
-			col.biome.CoverGround(x, z, baseX+int32(x), baseZ+int32(z), int(col.height), chunk)
 		}
 	}
+	if !g.conf.DisableCaves {
+		g.caves.carve(pos, chunk, m, heights, s, seaLevel)
+	}
+	g.placeOres(pos, chunk)
+	g.decorate(pos, chunk, decorated, seaLevel)
 }
 
-var stone = world.BlockRuntimeID(block.Stone{})
+var (
+	air       = world.BlockRuntimeID(block.Air{})
+	stone     = world.BlockRuntimeID(block.Stone{})
+	bedrock   = world.BlockRuntimeID(block.Bedrock{})
+	deepslate = stateRuntimeID("minecraft:deepslate", map[string]interface{}{"pillar_axis": "y"})
+	water     = world.BlockRuntimeID(block.Water{Still: true, Depth: 8})
+	lava      = world.BlockRuntimeID(block.Lava{Still: true, Depth: 8})
+)
 
-// displayDiagram displays the voronoi.Diagram passed in the chunk.Chunk passed by drawing the edges in the sky.
-func (g *Generator) displayDiagram(d *delaunay.Triangulation, height int, chunk *chunk.Chunk) {
-	iterateVoronoiEdges(d, func(pos delaunay.Point) {
-		if pos.X >= 0 && pos.X < 16 && pos.Y >= 0 && pos.Y < 16 {
-			chunk.SetBlock(uint8(pos.X), int16(height), uint8(pos.Y), 0, stone)
-		}
-	})
+// stateRuntimeID returns the runtime ID of a block state by its name and properties. It is used for blocks that are
+// not (yet) implemented by Dragonfly. stateRuntimeID panics if the block state does not exist.
+func stateRuntimeID(name string, properties map[string]interface{}) uint32 {
+	rid, ok := chunk.StateToRuntimeID(name, properties)
+	if !ok {
+		panic(fmt.Sprintf("cannot find block state %v %v", name, properties))
+	}
+	return rid
 }
 
-// cell finds the voronoi.Cell that a position was in in the voronoi.Diagram passed. If the cell was not found, false
-// is returned.
-func (g *Generator) cell(baseX, baseZ, absX, absZ int32, cells []cell) (cell, bool) {
+// cell finds the voronoi.Cell that a position was in using the cellIndex passed. The search for the cell is started
+// at the site with the index passed, which should be the site returned for a previous position close to this one. If
+// the cellIndex holds no cells, false is returned.
+func (g *Generator) cell(absX, absZ int32, cells *cellIndex, start int) (cell, int, bool) {
+	blur := g.conf.BlurStrength
 	v := delaunay.Point{
-		X: float64(absX-baseX) + (g.blurX(float64(absX)*0.9, float64(absZ)*0.9)-0.5)*50,
-		Y: float64(absZ-baseZ) + (g.blurZ(float64(absX)*0.9, float64(absZ)*0.9)-0.5)*50,
-	}
-	// Search all cells for one that contains the position we've got.
-	for _, c := range cells {
-		if c.inside(v) {
-			return c, true
-		}
+		X: float64(absX) + (g.blurX(float64(absX)*0.9, float64(absZ)*0.9)-0.5)*blur,
+		Y: float64(absZ) + (g.blurZ(float64(absX)*0.9, float64(absZ)*0.9)-0.5)*blur,
 	}
-	return cell{}, false
+	return cells.nearest(v, start)
 }
 
-// biome returns the Biome that a position was in based on the cell the position was in in the voronoi.Diagram passed.
-func (g *Generator) biome(baseX, baseZ, absX, absZ int32, cells []cell) Biome {
-	const freq = 0.05
-	ce, ok := g.cell(baseX, baseZ, absX, absZ, cells)
+// biome returns the Biome that a position was in based on the cell the position was in in the voronoi.Diagram passed,
+// along with the index of the site of that cell, which may be passed to the next call to speed up the search. If the
+// cellIndex holds no cells, the Biome is selected using the climate at the position itself.
+func (g *Generator) biome(absX, absZ int32, cells *cellIndex, start int) (Biome, int) {
+	ce, site, ok := g.cell(absX, absZ, cells, start)
 	if !ok {
-		// This really never should happen. If no cell is found, it means the settings passed to create the
-		// delaunay.Triangulation were not valid.
-		panic(fmt.Sprintf("Didn't find biome at [%v, %v]. Increase triangulation radius or increase point density", baseX, baseZ))
+		return g.selectBiome(float64(absX), float64(absZ)), 0
+	}
+	return g.cellBiome(ce), site
+}
+
+// cellBiome returns the Biome selected for a cell, based on the climate at the centre of the cell.
+func (g *Generator) cellBiome(ce cell) Biome {
+	p := ce.centre()
+	return g.selectBiome(p.X, p.Y)
+}
+
+// selectBiome selects a Biome using the climate at a specific x and z in the world.
+func (g *Generator) selectBiome(x, z float64) Biome {
+	return g.biomes.Select(g.climate(x, z))
+}
+
+// climate returns the Climate at the surface at a specific x and z in the world.
+func (g *Generator) climate(x, z float64) Climate {
+	x, z = x*g.conf.ClimateFrequency, z*g.conf.ClimateFrequency
+	return Climate{
+		Humidity:        g.hum(x, z),
+		Temperature:     g.temp(x, z),
+		Continentalness: g.cont(x, z),
+		Erosion:         g.erosion(x, z),
+		Weirdness:       g.weirdness(x, z),
 	}
-	p := ce.centre(float64(baseX), float64(baseZ))
-	return g.b.selectBiome(g.hum(p.X*freq, p.Y*freq), g.temp(p.X*freq, p.Y*freq))
 }
@@ -0,0 +1,149 @@
+package gen
+
+import (
+	"context"
+	"errors"
+	"github.com/df-mc/dragonfly/server/world"
+	"github.com/df-mc/dragonfly/server/world/chunk"
+	"runtime"
+	"sync"
+	"testing"
+	"time"
+)
+
+// TestGenerateChunkConcurrent generates overlapping chunks from many goroutines at the same time using one Generator,
+// so that the cell cache, caves, ores and decorations are used concurrently. It should be run with -race. Every chunk
+// must be generated exactly the same as when it is generated on its own.
+func TestGenerateChunkConcurrent(t *testing.T) {
+	g := NewWithConfig(Config{Seed: 1, CacheSize: 2})
+	positions := []world.ChunkPos{{0, 0}, {0, 1}, {1, 0}, {1, 1}, {31, 31}, {32, 32}, {-1, -1}}
+
+	want := make(map[world.ChunkPos]*chunk.Chunk, len(positions))
+	for _, pos := range positions {
+		c := chunk.New(air, world.Overworld.Range())
+		g.GenerateChunk(pos, c)
+		want[pos] = c
+	}
+
+	const goroutines = 8
+	var wg sync.WaitGroup
+	wg.Add(goroutines)
+	for i := 0; i < goroutines; i++ {
+		go func(i int) {
+			defer wg.Done()
+			for j := range positions {
+				pos := positions[(i+j)%len(positions)]
+				c := chunk.New(air, world.Overworld.Range())
+				g.GenerateChunk(pos, c)
+				if !equalChunks(c, want[pos]) {
+					t.Errorf("chunk %v generated concurrently differs from chunk generated on its own", pos)
+				}
+			}
+		}(i)
+	}
+	wg.Wait()
+}
+
+// equalChunks checks if the blocks and biomes of two chunks are equal.
+func equalChunks(a, b *chunk.Chunk) bool {
+	ra := a.Range()
+	for x := uint8(0); x < 16; x++ {
+		for z := uint8(0); z < 16; z++ {
+			for y := ra.Min(); y <= ra.Max(); y++ {
+				if a.Block(x, int16(y), z, 0) != b.Block(x, int16(y), z, 0) || a.Biome(x, int16(y), z) != b.Biome(x, int16(y), z) {
+					return false
+				}
+			}
+		}
+	}
+	return true
+}
+
+// TestGenerateChunkDeterministic checks that Generators created with the same seed generate the same chunks, and that
+// a Generator with a different seed generates different chunks.
+func TestGenerateChunkDeterministic(t *testing.T) {
+	a, b, other := NewWithConfig(Config{Seed: 7}), NewWithConfig(Config{Seed: 7}), NewWithConfig(Config{Seed: 8})
+	for _, pos := range []world.ChunkPos{{0, 0}, {5, -3}, {-40, 17}, {100000, -100000}} {
+		ca, cb, cother := chunk.New(air, world.Overworld.Range()), chunk.New(air, world.Overworld.Range()), chunk.New(air, world.Overworld.Range())
+		a.GenerateChunk(pos, ca)
+		b.GenerateChunk(pos, cb)
+		other.GenerateChunk(pos, cother)
+		if !equalChunks(ca, cb) {
+			t.Errorf("chunk %v differs between two generators with seed 7", pos)
+		}
+		if equalChunks(ca, cother) {
+			t.Errorf("chunk %v is the same for seed 7 and seed 8", pos)
+		}
+	}
+}
+
+// TestGenerateRegionCancel cancels GenerateRegion after a few chunks were received and checks that it returns the
+// error of the context, that no more chunks are sent afterwards and that all of its goroutines have stopped.
+func TestGenerateRegionCancel(t *testing.T) {
+	g := NewWithConfig(Config{Seed: 1, Workers: 4})
+	goroutines := runtime.NumGoroutine()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	out := make(chan GeneratedChunk)
+	errs := make(chan error, 1)
+	go func() {
+		errs <- g.GenerateRegion(ctx, world.ChunkPos{0, 0}, world.ChunkPos{63, 63}, out)
+	}()
+
+	for i := 0; i < 3; i++ {
+		select {
+		case <-out:
+		case <-time.After(time.Minute):
+			t.Fatal("timed out waiting for a chunk")
+		}
+	}
+	cancel()
+
+	select {
+	case err := <-errs:
+		if !errors.Is(err, context.Canceled) {
+			t.Fatalf("GenerateRegion returned %v, expected %v", err, context.Canceled)
+		}
+	case <-time.After(time.Minute):
+		t.Fatal("GenerateRegion did not return after its context was cancelled")
+	}
+	select {
+	case c := <-out:
+		t.Fatalf("chunk %v sent after GenerateRegion returned", c.Pos)
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	// Goroutines that have just finished may take a moment to be removed.
+	deadline := time.Now().Add(5 * time.Second)
+	for runtime.NumGoroutine() > goroutines {
+		if time.Now().After(deadline) {
+			t.Fatalf("%v goroutines running after GenerateRegion returned, expected %v", runtime.NumGoroutine(), goroutines)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+// TestGenerateChunkWorldFloor generates chunks of which the terrain is clamped to the bottom of the world, so that
+// biomes cover the ground right above the lowest block of the chunk.
+func TestGenerateChunkWorldFloor(t *testing.T) {
+	for _, conf := range []Config{{Seed: 1, BaseElevation: -200}, {Seed: 1, Amplitude: -1000}} {
+		g := NewWithConfig(conf)
+		for _, pos := range []world.ChunkPos{{0, 0}, {5, 9}, {-30, 40}} {
+			c := chunk.New(air, world.Overworld.Range())
+			g.GenerateChunk(pos, c)
+
+			minY := int16(c.Range().Min())
+			for x := uint8(0); x < 16; x++ {
+				for z := uint8(0); z < 16; z++ {
+					if c.Block(x, minY, z, 0) == air {
+						t.Fatalf("config %+v, chunk %v: no terrain at the bottom of column %v, %v", conf, pos, x, z)
+					}
+					if c.Block(x, int16(g.conf.SeaLevel), z, 0) != water {
+						t.Fatalf("config %+v, chunk %v: column %v, %v not filled with water", conf, pos, x, z)
+					}
+				}
+			}
+		}
+	}
+}
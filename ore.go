@@ -0,0 +1,208 @@
+package gen
+
+import (
+	"fmt"
+	"github.com/df-mc/dragonfly/server/block"
+	"github.com/df-mc/dragonfly/server/world"
+	"github.com/df-mc/dragonfly/server/world/biome"
+	"github.com/df-mc/dragonfly/server/world/chunk"
+	"math/rand"
+)
+
+// Distribution is the distribution of the heights at which the veins of an Ore are placed.
+type Distribution int
+
+const (
+	// Uniform places veins at any height between the minimum and maximum height with the same chance.
+	Uniform Distribution = iota
+	// Triangular places veins most often halfway between the minimum and maximum height. The chance of a vein being
+	// placed decreases linearly towards the minimum and maximum height.
+	Triangular
+)
+
+// Ore describes a kind of ore vein placed underground after caves have been carved.
+type Ore struct {
+	// Targets holds the blocks that veins of the Ore can replace, along with the block that they are replaced with.
+	// Blocks not present in Targets are left untouched.
+	Targets []OreTarget
+	// Size is the maximum amount of blocks in a single vein.
+	Size int
+	// Count is the amount of veins that are attempted to be placed in every chunk.
+	Count int
+	// MinY and MaxY are the minimum and maximum Y level of the origin of a vein. Veins may extend to heights outside
+	// of the vertical range of a chunk, in which case the blocks outside of the range are not placed.
+	MinY, MaxY int
+	// Distribution is the distribution of the heights that veins are placed at between MinY and MaxY.
+	Distribution Distribution
+	// Biomes holds the biomes in which veins of the Ore are placed. A vein is only placed if the Biome at its origin
+	// has the same ID as one of the biomes. If empty, veins are placed in any biome.
+	Biomes []world.Biome
+}
+
+// OreTarget is a block that an Ore may replace, along with the block it is replaced with.
+type OreTarget struct {
+	Replace, Block world.Block
+}
+
+// VanillaOres returns the ores placed in the overworld in vanilla: coal, iron, copper, gold, redstone, lapis, diamond
+// and emerald. All ores replace both stone and deepslate, using the deepslate variant of the ore for the latter.
+// Emerald ore is only placed in mountains.
+func VanillaOres() []Ore {
+	redstone := vanillaBlock("minecraft:redstone_ore", map[string]interface{}{})
+	deepslateRedstone := vanillaBlock("minecraft:deepslate_redstone_ore", map[string]interface{}{})
+
+	return []Ore{
+		{Targets: oreTargets(block.CoalOre{Type: block.StoneOre()}, block.CoalOre{Type: block.DeepslateOre()}), Size: 17, Count: 20, MinY: 0, MaxY: 192, Distribution: Triangular},
+		{Targets: oreTargets(block.IronOre{Type: block.StoneOre()}, block.IronOre{Type: block.DeepslateOre()}), Size: 9, Count: 10, MinY: -24, MaxY: 56, Distribution: Triangular},
+		{Targets: oreTargets(block.IronOre{Type: block.StoneOre()}, block.IronOre{Type: block.DeepslateOre()}), Size: 4, Count: 10, MinY: -64, MaxY: 72, Distribution: Uniform},
+		{Targets: oreTargets(block.CopperOre{Type: block.StoneOre()}, block.CopperOre{Type: block.DeepslateOre()}), Size: 10, Count: 16, MinY: -16, MaxY: 112, Distribution: Triangular},
+		{Targets: oreTargets(block.GoldOre{Type: block.StoneOre()}, block.GoldOre{Type: block.DeepslateOre()}), Size: 9, Count: 4, MinY: -64, MaxY: 32, Distribution: Triangular},
+		{Targets: oreTargets(redstone, deepslateRedstone), Size: 8, Count: 4, MinY: -64, MaxY: 15, Distribution: Uniform},
+		{Targets: oreTargets(redstone, deepslateRedstone), Size: 8, Count: 8, MinY: -96, MaxY: -32, Distribution: Triangular},
+		{Targets: oreTargets(block.LapisOre{Type: block.StoneOre()}, block.LapisOre{Type: block.DeepslateOre()}), Size: 7, Count: 2, MinY: -32, MaxY: 32, Distribution: Triangular},
+		{Targets: oreTargets(block.LapisOre{Type: block.StoneOre()}, block.LapisOre{Type: block.DeepslateOre()}), Size: 7, Count: 4, MinY: -64, MaxY: 64, Distribution: Uniform},
+		{Targets: oreTargets(block.DiamondOre{Type: block.StoneOre()}, block.DiamondOre{Type: block.DeepslateOre()}), Size: 4, Count: 7, MinY: -144, MaxY: 16, Distribution: Triangular},
+		{Targets: oreTargets(block.EmeraldOre{Type: block.StoneOre()}, block.EmeraldOre{Type: block.DeepslateOre()}), Size: 3, Count: 50, MinY: -16, MaxY: 480, Distribution: Triangular, Biomes: []world.Biome{biome.StonyPeaks{}}},
+	}
+}
+
+// oreTargets returns the OreTargets of an ore that replaces stone with its stone variant and deepslate with its
+// deepslate variant.
+func oreTargets(stoneVariant, deepslateVariant world.Block) []OreTarget {
+	return []OreTarget{
+		{Replace: block.Stone{}, Block: stoneVariant},
+		{Replace: vanillaBlock("minecraft:deepslate", map[string]interface{}{"pillar_axis": "y"}), Block: deepslateVariant},
+	}
+}
+
+// vanillaBlock returns a block by its name and properties. It is used for blocks that are not (yet) implemented by
+// Dragonfly. vanillaBlock panics if the block does not exist.
+func vanillaBlock(name string, properties map[string]interface{}) world.Block {
+	b, ok := world.BlockByName(name, properties)
+	if !ok {
+		panic(fmt.Sprintf("cannot find block %v %v", name, properties))
+	}
+	return b
+}
+
+// oreVeinRange is the maximum distance in blocks on the X and Z axis that a block of a vein may be placed from the
+// origin of the vein. It must not exceed 16, as only veins starting in neighbouring chunks are placed.
+const oreVeinRange = 8
+
+// ore is an Ore with its targets converted to runtime IDs.
+type ore struct {
+	Ore
+	targets map[uint32]uint32
+	biomes  map[uint32]struct{}
+}
+
+// newOre converts an Ore to an ore.
+func newOre(o Ore) ore {
+	compiled := ore{Ore: o, targets: make(map[uint32]uint32, len(o.Targets)), biomes: make(map[uint32]struct{}, len(o.Biomes))}
+	for _, t := range o.Targets {
+		compiled.targets[world.BlockRuntimeID(t.Replace)] = world.BlockRuntimeID(t.Block)
+	}
+	for _, b := range o.Biomes {
+		compiled.biomes[uint32(b.EncodeBiome())] = struct{}{}
+	}
+	return compiled
+}
+
+// placeOres places the ore veins of Config.Ores that pass through the chunk.Chunk at the world.ChunkPos passed. Veins
+// starting in neighbouring chunks may extend into this chunk, so the veins of all neighbouring chunks are computed too,
+// using a random source seeded with the position of the chunk that the veins start in. This way, veins line up over
+// chunk borders.
+func (g *Generator) placeOres(pos world.ChunkPos, c *chunk.Chunk) {
+	r := rand.New(new(splitMix))
+	minY, maxY := c.Range().Min(), c.Range().Max()
+	minX, minZ := int(pos[0])<<4, int(pos[1])<<4
+
+	for cx := pos[0] - 1; cx <= pos[0]+1; cx++ {
+		for cz := pos[1] - 1; cz <= pos[1]+1; cz++ {
+			r.Seed(chunkHash(g.conf.Seed^0x0e5, world.ChunkPos{cx, cz}))
+
+			// The cells of the chunk are looked up at most once, and every biome lookup starts its search at the site
+			// found for the vein before it, so that veins restricted to biomes stay cheap.
+			var (
+				cells       *cellIndex
+				cellsLoaded bool
+				site        int
+			)
+			for _, o := range g.ores {
+				for i := 0; i < o.Count; i++ {
+					x, z := int(cx)<<4+r.Intn(16), int(cz)<<4+r.Intn(16)
+					y := o.height(r)
+					if len(o.biomes) > 0 {
+						if !cellsLoaded {
+							cells, cellsLoaded = g.cells(world.ChunkPos{cx, cz}), true
+						}
+						var b Biome
+						b, site = g.biome(int32(x), int32(z), cells, site)
+						if _, ok := o.biomes[b.ID()]; !ok {
+							// Still consume the random values of the vein, so that the veins after this one are not
+							// influenced by the biome.
+							o.walk(r, x, y, z, func(int, int, int) {})
+							continue
+						}
+					}
+					o.walk(r, x, y, z, func(x, y, z int) {
+						if x < minX || x >= minX+16 || z < minZ || z >= minZ+16 || y < minY || y > maxY {
+							return
+						}
+						if rid, ok := o.targets[c.Block(uint8(x-minX), int16(y), uint8(z-minZ), 0)]; ok {
+							c.SetBlock(uint8(x-minX), int16(y), uint8(z-minZ), 0, rid)
+						}
+					})
+				}
+			}
+		}
+	}
+}
+
+// height returns a random height for the origin of a vein of the ore, following its Distribution.
+func (o ore) height(r *rand.Rand) int {
+	span := o.MaxY - o.MinY + 1
+	if span <= 0 {
+		return o.MinY
+	}
+	if o.Distribution == Triangular {
+		// The sum of two uniform random values has a triangular distribution.
+		return o.MinY + (r.Intn(span)+r.Intn(span))/2
+	}
+	return o.MinY + r.Intn(span)
+}
+
+// walk produces the positions of the blocks of a vein with its origin at x, y, z by randomly walking from the origin.
+// The function passed is called for every position in the vein.
+func (o ore) walk(r *rand.Rand, x, y, z int, f func(x, y, z int)) {
+	ox, oz := x, z
+	for i := 0; i < o.Size; i++ {
+		f(x, y, z)
+		switch r.Intn(6) {
+		case 0:
+			x++
+		case 1:
+			x--
+		case 2:
+			y++
+		case 3:
+			y--
+		case 4:
+			z++
+		case 5:
+			z--
+		}
+		x, z = clampInt(x, ox-oreVeinRange, ox+oreVeinRange), clampInt(z, oz-oreVeinRange, oz+oreVeinRange)
+	}
+}
+
+// clampInt clamps v between min and max.
+func clampInt(v, min, max int) int {
+	if v < min {
+		return min
+	}
+	if v > max {
+		return max
+	}
+	return v
+}
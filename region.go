@@ -0,0 +1,72 @@
+package gen
+
+import (
+	"context"
+	"github.com/df-mc/dragonfly/server/world"
+	"github.com/df-mc/dragonfly/server/world/chunk"
+	"sync"
+)
+
+// GeneratedChunk is a chunk.Chunk generated by Generator.GenerateRegion, along with its position.
+type GeneratedChunk struct {
+	Pos   world.ChunkPos
+	Chunk *chunk.Chunk
+}
+
+// GenerateRegion generates all chunks from min to max (inclusive) using Config.Workers goroutines and sends them to
+// the channel passed as they are generated, in no particular order. The chunks created have the vertical range set in
+// Config.Range. GenerateRegion is intended for pre-generating an area of the world.
+// GenerateRegion blocks until all chunks are generated and sent, or until the context.Context passed is cancelled,
+// in which case the error of the context is returned. The channel passed is not closed by GenerateRegion.
+func (g *Generator) GenerateRegion(ctx context.Context, min, max world.ChunkPos, out chan<- GeneratedChunk) error {
+	positions := make(chan world.ChunkPos)
+
+	var wg sync.WaitGroup
+	wg.Add(g.conf.Workers)
+	for i := 0; i < g.conf.Workers; i++ {
+		go func() {
+			defer wg.Done()
+			for pos := range positions {
+				c := chunk.New(air, g.conf.Range)
+				g.GenerateChunk(pos, c)
+				select {
+				case out <- GeneratedChunk{Pos: pos, Chunk: c}:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}()
+	}
+
+	// Chunks are handed out region by region, so that workers generating chunks at the same time share the voronoi
+	// cells cached for their region.
+	minRegion, maxRegion := regionPos(min), regionPos(max)
+feed:
+	for rx := minRegion[0]; rx <= maxRegion[0]; rx++ {
+		for rz := minRegion[1]; rz <= maxRegion[1]; rz++ {
+			for x := clamp32(rx<<regionShift, min[0], max[0]); x <= clamp32(rx<<regionShift+regionSize-1, min[0], max[0]); x++ {
+				for z := clamp32(rz<<regionShift, min[1], max[1]); z <= clamp32(rz<<regionShift+regionSize-1, min[1], max[1]); z++ {
+					select {
+					case positions <- world.ChunkPos{x, z}:
+					case <-ctx.Done():
+						break feed
+					}
+				}
+			}
+		}
+	}
+	close(positions)
+	wg.Wait()
+	return ctx.Err()
+}
+
+// clamp32 clamps v between min and max.
+func clamp32(v, min, max int32) int32 {
+	if v < min {
+		return min
+	}
+	if v > max {
+		return max
+	}
+	return v
+}
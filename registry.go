@@ -0,0 +1,158 @@
+package gen
+
+import (
+	"math"
+)
+
+// Climate is the climate at a position in the world. It is used to select the Biome at that position from a
+// BiomeRegistry. Apart from Depth, all values are roughly in the range [0-1), with most values close to 0.5.
+type Climate struct {
+	// Humidity and Temperature specify how wet and how warm the climate is.
+	Humidity, Temperature float64
+	// Continentalness specifies how far inland a position is. Low values are found far out at sea, high values far
+	// inland. Continentalness changes slower than the other values, so that it forms large, coherent continents and
+	// oceans. Apart from selecting biomes, it lowers the ocean floor far out at sea and raises the land far inland.
+	Continentalness float64
+	// Erosion specifies how eroded the terrain is. Low values are found in rough, mountainous terrain, while high
+	// values are found in flat terrain.
+	Erosion float64
+	// Weirdness varies the biome selected for otherwise similar climates. Rivers are found where the weirdness is
+	// close to 0.5, in the valleys between peaks and plateaus.
+	Weirdness float64
+	// Depth is the depth in blocks below the surface of the terrain. It is 0 at the surface, where the biomes that
+	// shape the terrain are selected, and is only used to select underground biomes below it.
+	Depth float64
+}
+
+// Interval is an interval of climate values, ranging from Min up to, but not including, Max. The zero value of an
+// Interval contains any value.
+type Interval struct {
+	Min, Max float64
+}
+
+// distance returns the distance of the value passed to the Interval, or 0 if it is in the Interval.
+func (i Interval) distance(v float64) float64 {
+	switch {
+	case i == Interval{}:
+		return 0
+	case v < i.Min:
+		return i.Min - v
+	case v >= i.Max:
+		return v - i.Max
+	}
+	return 0
+}
+
+// ClimateRange is a range of climates in which a Biome is selected. Parameters for which no Interval is set match any
+// value.
+type ClimateRange struct {
+	Humidity, Temperature Interval
+	Continentalness       Interval
+	Erosion               Interval
+	Weirdness             Interval
+	Depth                 Interval
+}
+
+// distance returns the distance of the Climate passed to the closest climate in the ClimateRange, or 0 if the Climate is
+// in the ClimateRange.
+func (r ClimateRange) distance(c Climate) float64 {
+	var sum float64
+	for _, d := range [...]float64{
+		r.Humidity.distance(c.Humidity),
+		r.Temperature.distance(c.Temperature),
+		r.Continentalness.distance(c.Continentalness),
+		r.Erosion.distance(c.Erosion),
+		r.Weirdness.distance(c.Weirdness),
+		r.Depth.distance(c.Depth),
+	} {
+		sum += d * d
+	}
+	return math.Sqrt(sum)
+}
+
+// underground checks if the ClimateRange is that of an underground biome, which is only found below the surface.
+func (r ClimateRange) underground() bool {
+	return r.Depth.Min > 0
+}
+
+// BiomeRegistry holds the biomes that a Generator selects from, along with the ClimateRange in which each of them is
+// selected. Custom biomes may be added to a BiomeRegistry using Register, after which it may be passed to the Biomes
+// field of Config.
+// A BiomeRegistry must not be changed after it has been passed to NewWithConfig.
+type BiomeRegistry struct {
+	biomes []registeredBiome
+	// underground specifies if any underground biomes are registered.
+	underground bool
+}
+
+// registeredBiome is a Biome registered to a BiomeRegistry.
+type registeredBiome struct {
+	b Biome
+	r ClimateRange
+}
+
+// NewBiomeRegistry returns a new, empty BiomeRegistry. Use DefaultBiomes to obtain a BiomeRegistry holding the biomes
+// of package biome.
+func NewBiomeRegistry() *BiomeRegistry {
+	return &BiomeRegistry{}
+}
+
+// Register registers a Biome to the BiomeRegistry, so that it is selected for climates within the ClimateRange passed.
+// If the ClimateRanges of multiple biomes overlap, the Biome registered first is selected.
+// If the Depth Interval of the ClimateRange has a Min above 0, the Biome is an underground biome. Underground biomes
+// are never selected at the surface. Instead, an underground biome is written to the chunk for the blocks below the
+// surface of which the Climate, with Depth set to the depth of the block, is within its ClimateRange. The terrain below
+// an underground biome is that of the biome at the surface, so its CoverGround and Height methods are not used.
+func (r *BiomeRegistry) Register(b Biome, climate ClimateRange) {
+	r.biomes = append(r.biomes, registeredBiome{b: b, r: climate})
+	r.underground = r.underground || climate.underground()
+}
+
+// Biomes returns all biomes registered to the BiomeRegistry, in the order that they were registered.
+func (r *BiomeRegistry) Biomes() []Biome {
+	biomes := make([]Biome, len(r.biomes))
+	for i, b := range r.biomes {
+		biomes[i] = b.b
+	}
+	return biomes
+}
+
+// Select selects the Biome of which the ClimateRange is nearest to the Climate passed in the climate parameter space.
+// If the ClimateRanges of multiple biomes contain the Climate, the Biome registered first is selected. If the Climate
+// has a Depth above 0 and is within the ClimateRange of an underground biome, that biome is selected. Otherwise, only
+// biomes found at the surface are selected from. Select returns nil if the BiomeRegistry holds no such biomes.
+func (r *BiomeRegistry) Select(c Climate) Biome {
+	if b, ok := r.selectUnderground(c); ok {
+		return b
+	}
+	var (
+		closest Biome
+		dist    = math.Inf(1)
+	)
+	for _, b := range r.biomes {
+		if b.r.underground() {
+			continue
+		}
+		if d := b.r.distance(c); d < dist {
+			if d == 0 {
+				return b.b
+			}
+			closest, dist = b.b, d
+		}
+	}
+	return closest
+}
+
+// selectUnderground selects the underground biome of which the ClimateRange contains the Climate passed. If the
+// Climate has a Depth of 0 or no underground biome contains it, false is returned.
+func (r *BiomeRegistry) selectUnderground(c Climate) (Biome, bool) {
+	if !r.underground || c.Depth <= 0 {
+		return nil, false
+	}
+	for _, b := range r.biomes {
+		if b.r.underground() && b.r.distance(c) == 0 {
+			return b.b, true
+		}
+	}
+	return nil, false
+}
@@ -0,0 +1,75 @@
+package gen
+
+import (
+	"github.com/df-mc/dragonfly/server/world"
+	"github.com/df-mc/dragonfly/server/world/chunk"
+	"github.com/df-mc/gen/biome"
+	"github.com/df-mc/gen/f"
+	"testing"
+)
+
+func TestBiomeRegistrySelect(t *testing.T) {
+	n := f.Noise(1, 3, 2, 0.5).Norm()
+	ocean, plains, forest := &biome.Ocean{Noise: n}, &biome.Plains{Noise: n}, &biome.Forest{Noise: n}
+	caves := &biome.Generic{BiomeID: 1}
+
+	r := NewBiomeRegistry()
+	r.Register(ocean, ClimateRange{Continentalness: Interval{0, 0.4}})
+	r.Register(caves, ClimateRange{Humidity: Interval{0.5, 1}, Depth: Interval{10, 100}})
+	r.Register(plains, ClimateRange{Continentalness: Interval{0.4, 1}, Humidity: Interval{0, 0.5}})
+	r.Register(forest, ClimateRange{Continentalness: Interval{0.4, 1}, Humidity: Interval{0.5, 1}})
+
+	for _, test := range []struct {
+		c    Climate
+		want Biome
+	}{
+		{Climate{Continentalness: 0.2, Humidity: 0.7}, ocean},
+		{Climate{Continentalness: 0.6, Humidity: 0.2}, plains},
+		{Climate{Continentalness: 0.6, Humidity: 0.7}, forest},
+		// No biome contains this climate, so the nearest biome is selected.
+		{Climate{Continentalness: 0.6, Humidity: 1.1}, forest},
+		// The underground biome is only selected within its depth.
+		{Climate{Continentalness: 0.6, Humidity: 0.7, Depth: 5}, forest},
+		{Climate{Continentalness: 0.6, Humidity: 0.7, Depth: 10}, caves},
+		{Climate{Continentalness: 0.2, Humidity: 0.7, Depth: 50}, caves},
+		{Climate{Continentalness: 0.6, Humidity: 0.7, Depth: 150}, forest},
+		{Climate{Continentalness: 0.6, Humidity: 0.2, Depth: 50}, plains},
+		// Underground biomes are never the nearest biome at the surface, even if they are closest.
+		{Climate{Continentalness: 0.6, Humidity: 0.7, Depth: 0}, forest},
+		{Climate{Continentalness: 0.1, Humidity: 0.7, Depth: 5}, ocean},
+	} {
+		if got := r.Select(test.c); got != test.want {
+			t.Errorf("Select(%+v): %T, expected %T", test.c, got, test.want)
+		}
+	}
+}
+
+func TestGenerateChunkUnderground(t *testing.T) {
+	conf := Config{Seed: 1, DisableCaves: true}
+	r := DefaultBiomes(conf)
+	caves := &biome.Generic{BiomeID: 174}
+	r.Register(caves, ClimateRange{Depth: Interval{20, 10000}})
+	conf.Biomes = r
+	g := NewWithConfig(conf)
+
+	pos, c := world.ChunkPos{3, 4}, chunk.New(air, world.Overworld.Range())
+	g.GenerateChunk(pos, c)
+	// The underground biome must fill every column from the bottom up to 20 blocks below the surface.
+	radius := g.conf.SmoothingRadius
+	m := calculateTerrainMap(radius+decorationMargin, pos, g, c).smooth(radius, normalCurve).inner(decorationMargin)
+	for x := uint8(0); x < 16; x++ {
+		for z := uint8(0); z < 16; z++ {
+			height := int(m[x+z*16].height)
+			surface := m[x+z*16].biome.ID()
+			for y := c.Range().Min(); y <= c.Range().Max(); y++ {
+				want := surface
+				if y <= height-20 {
+					want = caves.ID()
+				}
+				if got := c.Biome(x, int16(y), z); got != want {
+					t.Fatalf("column %v, %v: biome %v at depth %v, expected %v", x, z, got, height-y, want)
+				}
+			}
+		}
+	}
+}
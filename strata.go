@@ -0,0 +1,66 @@
+package gen
+
+import (
+	"github.com/df-mc/dragonfly/server/block/cube"
+)
+
+// Strata describes the layers of rock that are placed below the surface of the terrain, such as the bedrock floor at
+// the bottom of the world and the deepslate found deep underground.
+type Strata struct {
+	// BedrockThickness is the maximum thickness of the bedrock floor at the bottom of the world. The lowest layer is
+	// always fully bedrock, while the chance of bedrock appearing in the layers above decreases linearly until it
+	// reaches 0 at BedrockThickness layers above the bottom. A value of 0 disables the bedrock floor.
+	BedrockThickness int
+	// DeepslateLevel is the Y level below which all stone is replaced with deepslate.
+	DeepslateLevel int
+	// DeepslateTransition is the height of the band above DeepslateLevel in which stone gradually transitions into
+	// deepslate. The chance of a block in this band being deepslate decreases linearly with its height.
+	DeepslateTransition int
+	// LavaLevel is the Y level at and below which caves carved are filled with lava instead of air.
+	LavaLevel int
+}
+
+// DefaultStrata returns the Strata used for chunks with the cube.Range passed if Config.Strata holds no Strata for it.
+// Ranges that extend below Y=0 get deepslate below Y=0, similar to vanilla. Ranges that do not only get a bedrock
+// floor. Caves in the bottom 10 layers of the range are filled with lava.
+func DefaultStrata(r cube.Range) Strata {
+	if r.Min() < 0 {
+		return Strata{BedrockThickness: 5, DeepslateLevel: 0, DeepslateTransition: 8, LavaLevel: r.Min() + 9}
+	}
+	return Strata{BedrockThickness: 5, DeepslateLevel: r.Min(), LavaLevel: r.Min() + 9}
+}
+
+// strata returns the Strata used for chunks with the cube.Range passed.
+func (g *Generator) strata(r cube.Range) Strata {
+	if s, ok := g.conf.Strata[r]; ok {
+		return s
+	}
+	return DefaultStrata(r)
+}
+
+// rock returns the runtime ID of the rock block that should be placed at a specific position below the surface,
+// considering the Strata passed. The bottom of the world is passed as minY.
+func (g *Generator) rock(s Strata, minY, x, y, z int) uint32 {
+	if y-minY < s.BedrockThickness && layerChance(y-minY, s.BedrockThickness) > g.jitter(x, y, z) {
+		return bedrock
+	}
+	if y < s.DeepslateLevel {
+		return deepslate
+	}
+	if y-s.DeepslateLevel < s.DeepslateTransition && layerChance(y-s.DeepslateLevel, s.DeepslateTransition) > g.jitter(x, y, z) {
+		return deepslate
+	}
+	return stone
+}
+
+// jitter returns a deterministic pseudo-random value in the range [0-1) for a block position. It is used to create
+// jagged transitions between layers of rock.
+func (g *Generator) jitter(x, y, z int) float64 {
+	return float64(posHash(g.conf.Seed, x, y, z)>>11) / (1 << 53)
+}
+
+// layerChance returns the chance [0-1] that a block n layers into a band of a specific thickness is of the block
+// that the band is transitioning from.
+func layerChance(n, thickness int) float64 {
+	return 1 - float64(n)/float64(thickness)
+}
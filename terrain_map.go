@@ -19,21 +19,25 @@ type terrainMap []terrainColumn
 // calculateTerrainMap calculates a terrainMap at a specific world.ChunkPos. The r value passed specifies how much space
 // around the chunk's bounds is also calculated to prepare for smoothing the terrain map.
 func calculateTerrainMap(r int, pos world.ChunkPos, g *Generator, chunk *chunk.Chunk) terrainMap {
-	d := triangulate(pos, 20, 0.06)
-	cells := voronoiCells(d)
-	g.displayDiagram(d, 128, chunk)
+	cells := g.cells(pos)
+	if g.conf.Debug {
+		g.displayDiagram(pos, cells, chunk)
+	}
 
 	baseX, baseY := int(pos[0]<<4), int(pos[1]<<4)
 
 	dx := 2*r + 16
 	m := make(terrainMap, dx*dx)
 
+	var site int
 	for x := -r; x < 16+r; x++ {
 		for y := -r; y < 16+r; y++ {
-			biome := g.biome(int32(baseX), int32(baseY), int32(x+baseX), int32(y+baseY), cells)
+			var biome Biome
+			biome, site = g.biome(int32(x+baseX), int32(y+baseY), cells, site)
 
+			absX, absY := float64(baseX+x), float64(baseY+y)
 			m[(x+r)+(y+r)*dx] = terrainColumn{
-				height: biome.Height(float64(baseX+x), float64(baseY+y)) * 128,
+				height: float64(g.conf.BaseElevation) + (biome.Height(absX, absY)+g.elevation(absX, absY))*g.conf.Amplitude,
 				biome:  biome,
 			}
 		}
@@ -43,12 +47,12 @@ func calculateTerrainMap(r int, pos world.ChunkPos, g *Generator, chunk *chunk.C
 
 // smooth smooths the terrainMap where r specifies the radius of the circle around a column that influences the final
 // height of a block. The curve passed has an influence on the weight of another height around a column at a specific
-// distance.
+// distance. The terrainMap returned is r columns smaller than m on every side.
 func (m terrainMap) smooth(r int, c curve) terrainMap {
 	var (
 		rf            = float64(r)
 		curveStepSize = float64(len(c)) / rf
-		dx            = 16
+		dx            = int(math.Sqrt(float64(len(m)))) - r*2
 		smooth        = make(terrainMap, dx*dx)
 		norm, height  float64
 		biome         Biome
@@ -90,3 +94,44 @@ func (m terrainMap) smooth(r int, c curve) terrainMap {
 	}
 	return smooth
 }
+
+// inner returns the 16x16 columns of a terrainMap that extends margin columns beyond the borders of a chunk on every
+// side.
+func (m terrainMap) inner(margin int) terrainMap {
+	if margin == 0 {
+		return m
+	}
+	dx := 16 + margin*2
+	inner := make(terrainMap, 256)
+	for z := 0; z < 16; z++ {
+		copy(inner[z*16:z*16+16], m[margin+(z+margin)*dx:])
+	}
+	return inner
+}
+
+// cells returns a cellIndex holding the voronoi cells of the region that the world.ChunkPos passed is in. The cells of
+// a region are cached, so that neighbouring chunks do not need to compute them again.
+func (g *Generator) cells(pos world.ChunkPos) *cellIndex {
+	return g.cache.cells(regionPos(pos), g.regionCells)
+}
+
+// regionCells computes the voronoi cells of the region at the position passed. The cells are computed from a
+// triangulation that extends Config.TriangulationRadius chunks beyond the region, so that the cells close to the edge
+// of the region line up with those of neighbouring regions. If no triangulation could be created, it is retried with
+// a bigger radius. If this fails too, the error is logged and nil is returned.
+func (g *Generator) regionCells(region world.ChunkPos) *cellIndex {
+	const attempts = 3
+	radius := g.conf.TriangulationRadius
+	for i := 0; i < attempts; i++ {
+		min := world.ChunkPos{region[0]<<regionShift - radius, region[1]<<regionShift - radius}
+		max := world.ChunkPos{region[0]<<regionShift + regionSize - 1 + radius, region[1]<<regionShift + regionSize - 1 + radius}
+
+		d, err := triangulate(g.conf.Seed, min, max, g.conf.PointDensity)
+		if err == nil {
+			return newCellIndex(d, voronoiCells(d))
+		}
+		g.conf.Log.Errorf("gen: %v (radius %v, attempt %v/%v)", err, radius, i+1, attempts)
+		radius *= 2
+	}
+	return nil
+}
@@ -1,32 +1,36 @@
 package gen
 
 import (
+	"fmt"
 	"github.com/df-mc/dragonfly/server/world"
 	"github.com/fogleman/delaunay"
 	"math"
 	"math/rand"
 )
 
-// triangulate creates a delaunay.Triangulation centred around the world.ChunkPos passed. The triangulation created is
-// deterministic for that specific world.ChunkPos.
-// The diagramRadius passed specifies the radius of the triangulation. A bigger radius means more accurately aligning cells
-// are created in neighbouring chunks. Depending on the pointDensity passed, which indirectly influences the density of
-// voronoi cells, the diagramRadius should be increased or decreased. A higher pointDensity results in smaller cells,
-// which doesn't require as large of a triangulation to be accurate. The pointDensity is a value [0-1) that specifies the
-// likelihood that a chunk contains one node of a voronoi cell.
-func triangulate(pos world.ChunkPos, diagramRadius int32, pointDensity float64) *delaunay.Triangulation {
-	r := rand.New(rand.NewSource(chunkHash(pos)))
-	d := float64(diagramRadius*2 + 1)
+// triangulate creates a delaunay.Triangulation of the points generated in all chunks from min to max (inclusive). The
+// points of the triangulation are in absolute block coordinates. The triangulation created is deterministic for that
+// specific seed and area, and the points generated in a chunk are the same regardless of the area passed, so that
+// triangulations of overlapping areas produce the same voronoi cells in the area where they overlap, apart from close
+// to their edges.
+// The pointDensity is a value [0-1) that specifies the likelihood that a chunk contains one node of a voronoi cell. A
+// higher pointDensity results in smaller cells, which require less of a margin around the area that is used to be
+// accurate.
+// An error is returned if no triangulation could be created from the points generated, which may happen if too few
+// points are generated.
+func triangulate(seed int64, min, max world.ChunkPos, pointDensity float64) (*delaunay.Triangulation, error) {
+	r := rand.New(new(splitMix))
+	w, h := float64(max[0]-min[0]+1), float64(max[1]-min[1]+1)
 
 	// Make a rough estimate of the amount of points we'll generate. Assuming the chance a point is generated in a chunk
 	// is pointDensity/1, we should be able to multiply that by the total amount of chunks and get a rough estimate.
-	points := make([]delaunay.Point, 0, int(d*d*pointDensity))
+	points := make([]delaunay.Point, 0, int(w*h*pointDensity*1.5))
 
-	for x := -diagramRadius; x <= diagramRadius; x++ {
-		for z := -diagramRadius; z <= diagramRadius; z++ {
+	for x := min[0]; x <= max[0]; x++ {
+		for z := min[1]; z <= max[1]; z++ {
 			// Seed the random instance with the chunk hash of this specific chunk position so that we get deterministic
 			// results per chunk.
-			r.Seed(chunkHash(world.ChunkPos{pos[0] + x, pos[1] + z}))
+			r.Seed(chunkHash(seed, world.ChunkPos{x, z}))
 
 			// Increase the density based on a random value, this makes it possible to have more detailed and less
 			// consistent biome edges in some places and reduces the general consistency of point spacing.
@@ -37,38 +41,41 @@ func triangulate(pos world.ChunkPos, diagramRadius int32, pointDensity float64)
 				// produced coordinate.
 				v := r.Int31()
 				points = append(points, delaunay.Point{
-					X: float64(x<<4 + (v & 0xf)),
-					Y: float64(z<<4 + ((v >> 4) & 0xf)),
+					X: float64(int64(x)<<4 + int64(v&0xf)),
+					Y: float64(int64(z)<<4 + int64((v>>4)&0xf)),
 				})
 			}
 		}
 	}
+	if len(points) < 3 {
+		return nil, fmt.Errorf("triangulate chunks %v-%v: need at least 3 points, got %v", min, max, len(points))
+	}
 	t, err := delaunay.Triangulate(points)
 	if err != nil {
-		panic(err)
+		return nil, fmt.Errorf("triangulate %v points in chunks %v-%v: %w", len(points), min, max, err)
 	}
-	return t
+	return t, nil
 }
 
+// cell is a cell in a voronoi diagram. It holds the index of its site, which is the point of the triangulation that
+// the cell was created around, and the corners of the cell.
 type cell struct {
+	index   int
 	corners []delaunay.Point
 }
 
-func (c cell) inside(v delaunay.Point) bool {
-	l := len(c.corners) - 1
-	for i, a := range c.corners {
-		b := c.corners[l]
-		if i != 0 {
-			b = c.corners[i-1]
-		}
-		if (b.X-a.X)*(v.Y-a.Y)-(b.Y-a.Y)*(v.X-a.X) > 0 {
-			return false
-		}
+// intersects checks if the bounding box of the cell intersects with the area from minX, minY to maxX, maxY.
+func (c cell) intersects(minX, minY, maxX, maxY float64) bool {
+	cMinX, cMinY, cMaxX, cMaxY := math.Inf(1), math.Inf(1), math.Inf(-1), math.Inf(-1)
+	for _, corner := range c.corners {
+		cMinX, cMinY = math.Min(cMinX, corner.X), math.Min(cMinY, corner.Y)
+		cMaxX, cMaxY = math.Max(cMaxX, corner.X), math.Max(cMaxY, corner.Y)
 	}
-	return true
+	return cMinX < maxX && cMaxX >= minX && cMinY < maxY && cMaxY >= minY
 }
 
-func (c cell) centre(baseX, baseY float64) delaunay.Point {
+// centre returns the centre of the cell, which is the average of its corners.
+func (c cell) centre() delaunay.Point {
 	p := delaunay.Point{}
 	for _, corner := range c.corners {
 		p.X += corner.X
@@ -77,8 +84,6 @@ func (c cell) centre(baseX, baseY float64) delaunay.Point {
 	l := float64(len(c.corners))
 	p.X /= l
 	p.Y /= l
-	p.X += baseX
-	p.Y += baseY
 	return p
 }
 
@@ -97,7 +102,7 @@ func voronoiCells(d *delaunay.Triangulation) []cell {
 			for i, t := range triangles {
 				points[i] = triangleCentre(d, t)
 			}
-			cells = append(cells, cell{corners: points})
+			cells = append(cells, cell{index: p, corners: points})
 		}
 	}
 	return cells
@@ -116,20 +121,14 @@ func edgesAroundPoint(d *delaunay.Triangulation, start int) []int {
 	return p
 }
 
-func iterateVoronoiEdges(d *delaunay.Triangulation, f func(pos delaunay.Point)) {
-	for e := 0; e < len(d.Triangles); e++ {
-		if e < d.Halfedges[e] {
-			a := triangleCentre(d, triangleOfEdge(e))
-			b := triangleCentre(d, triangleOfEdge(d.Halfedges[e]))
-
-			diffX, diffY := b.X-a.X, b.Y-a.Y
-			dist := math.Sqrt(diffX*diffX + diffY*diffY)
-			stepX, stepY := diffX/dist, diffY/dist
+// iterateLine calls the function passed for points spaced one block apart on the line between a and b.
+func iterateLine(a, b delaunay.Point, f func(pos delaunay.Point)) {
+	diffX, diffY := b.X-a.X, b.Y-a.Y
+	dist := math.Sqrt(diffX*diffX + diffY*diffY)
+	stepX, stepY := diffX/dist, diffY/dist
 
-			for step := 0.0; step < dist; step++ {
-				f(delaunay.Point{X: a.X + stepX*step, Y: a.Y + stepY*step})
-			}
-		}
+	for step := 0.0; step < dist; step++ {
+		f(delaunay.Point{X: a.X + stepX*step, Y: a.Y + stepY*step})
 	}
 }
 
@@ -169,8 +168,49 @@ func nextHalfEdge(e int) int {
 	return e + 1
 }
 
-// chunkHash produces a unique chunk hash for the position passed for chunk positions up to math.MaxInt16. Go random
-// seeds only use 32 bits of the int64 you pass, so this function only uses the first 32 bits of the int64.
-func chunkHash(pos world.ChunkPos) int64 {
-	return int64((uint64(uint16(pos[0])) << 16) | uint64(uint16(pos[1])))
+// chunkHash produces a 64-bit hash of the seed and the chunk position passed. Every bit of the seed and both
+// coordinates influences the hash, so that hashes do not repeat for different positions within the world border and
+// differ between seeds.
+func chunkHash(seed int64, pos world.ChunkPos) int64 {
+	h := mix64(uint64(seed) ^ 0x9e3779b97f4a7c15)
+	h = mix64(h ^ uint64(uint32(pos[0])))
+	h = mix64(h ^ uint64(uint32(pos[1]))<<32)
+	return int64(h)
+}
+
+// posHash produces a 64-bit hash of the seed and the block position passed, similarly to chunkHash.
+func posHash(seed int64, x, y, z int) uint64 {
+	h := mix64(uint64(seed) ^ 0x6a09e667f3bcc909)
+	h = mix64(h ^ uint64(uint32(x)))
+	h = mix64(h ^ uint64(uint32(y)))
+	return mix64(h ^ uint64(uint32(z)))
+}
+
+// mix64 is the finaliser of the splitmix64 generator. It scrambles the bits of the value passed so that values close
+// to each other produce unrelated results.
+func mix64(z uint64) uint64 {
+	z = (z ^ (z >> 30)) * 0xbf58476d1ce4e5b9
+	z = (z ^ (z >> 27)) * 0x94d049bb133111eb
+	return z ^ (z >> 31)
+}
+
+// splitMix is a rand.Source64 implementing the splitmix64 generator. Unlike the source returned by rand.NewSource,
+// which reduces its seed to 31 bits, it uses all 64 bits of the seed passed. It is also considerably cheaper to
+// seed.
+type splitMix uint64
+
+// Seed ...
+func (s *splitMix) Seed(seed int64) {
+	*s = splitMix(seed)
+}
+
+// Uint64 ...
+func (s *splitMix) Uint64() uint64 {
+	*s += 0x9e3779b97f4a7c15
+	return mix64(uint64(*s))
+}
+
+// Int63 ...
+func (s *splitMix) Int63() int64 {
+	return int64(s.Uint64() >> 1)
 }
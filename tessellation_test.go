@@ -0,0 +1,35 @@
+package gen
+
+import (
+	"github.com/df-mc/dragonfly/server/world"
+	"testing"
+)
+
+func TestChunkHash(t *testing.T) {
+	// Chunk positions reach up to ±1875000 within the world border, which is well beyond the ±32768 that fits in 16
+	// bits, so positions that only differ in their upper bits must hash differently.
+	var positions []world.ChunkPos
+	for _, x := range []int32{0, 1, -1, 32767, 32768, -32768, -32769, 65536, -65536, 65537, 100000, 1875000, -1875000} {
+		for _, z := range []int32{0, 1, -1, 32768, -32769, 65536, -65536, 1875000, -1875000} {
+			positions = append(positions, world.ChunkPos{x, z})
+		}
+	}
+	for _, seed := range []int64{0, 1, -1, 1 << 32, 1 << 48} {
+		seen := make(map[int64]world.ChunkPos, len(positions))
+		for _, pos := range positions {
+			h := chunkHash(seed, pos)
+			if other, ok := seen[h]; ok {
+				t.Errorf("seed %v: chunks %v and %v have the same hash %v", seed, pos, other, h)
+			}
+			seen[h] = pos
+			if h != chunkHash(seed, pos) {
+				t.Errorf("seed %v: hash of chunk %v is not deterministic", seed, pos)
+			}
+		}
+	}
+	for _, pos := range positions {
+		if a, b := chunkHash(1, pos), chunkHash(1<<32+1, pos); a == b {
+			t.Errorf("chunk %v: same hash %v for seeds that only differ in their upper 32 bits", pos, a)
+		}
+	}
+}